@@ -0,0 +1,87 @@
+// Package compiler lexes, parses and type checks Lox source, reporting problems of
+// every phase as Diagnostics. It's shared by lox.Compile and Interpreter.Eval
+package compiler
+
+import (
+	"errors"
+	"fmt"
+	"lox/lexer"
+	"lox/parser"
+	"lox/typecheck"
+	"strings"
+)
+
+// Phase is the step of compilation which found the problem
+type Phase string
+
+const (
+	PhaseLex   Phase = "lex"
+	PhaseParse Phase = "parse"
+	PhaseType  Phase = "type"
+)
+
+// Diagnostic is a problem found while compiling the source called Name, Line is 0 when it's unknown
+type Diagnostic struct {
+	Name    string
+	Phase   Phase
+	Line    int
+	Message string
+}
+
+func (d Diagnostic) Error() string {
+	if d.Line == 0 {
+		return fmt.Sprintf("%v: %v error: %v", d.Name, d.Phase, d.Message)
+	}
+	return fmt.Sprintf("%v:%d: %v error: %v", d.Name, d.Line, d.Phase, d.Message)
+}
+
+// Diagnostics is the error of a source which didn't compile, one line per diagnostic
+type Diagnostics []Diagnostic
+
+func (ds Diagnostics) Error() string {
+	lines := []string{}
+	for _, d := range ds {
+		lines = append(lines, d.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Compile lexes, parses and type checks the source with the checker, which knows
+// declarations of sources checked by it before. Statements are nil when there are diagnostics
+func Compile(name, src string, checker *typecheck.Checker) ([]parser.Statement, Diagnostics) {
+	toks, err := lexer.Lex(src)
+	if err != nil {
+		d := Diagnostic{Name: name, Phase: PhaseLex, Message: err.Error()}
+		var lexErr lexer.Error
+		if errors.As(err, &lexErr) {
+			d.Line = lexErr.Line
+		}
+		return nil, Diagnostics{d}
+	}
+	stmts, errs := parser.NewParser(toks).Parse()
+	if len(errs) != 0 {
+		diagnostics := Diagnostics{}
+		for _, err := range errs {
+			d := Diagnostic{Name: name, Phase: PhaseParse, Message: err.Error()}
+			var parseErr parser.Error
+			if errors.As(err, &parseErr) {
+				d.Line = parseErr.Line
+			}
+			diagnostics = append(diagnostics, d)
+		}
+		return nil, diagnostics
+	}
+	if errs := checker.Check(stmts); len(errs) != 0 {
+		diagnostics := Diagnostics{}
+		for _, err := range errs {
+			d := Diagnostic{Name: name, Phase: PhaseType, Message: err.Error()}
+			var typeErr typecheck.Error
+			if errors.As(err, &typeErr) {
+				d.Line, d.Message = typeErr.Line, typeErr.Message
+			}
+			diagnostics = append(diagnostics, d)
+		}
+		return nil, diagnostics
+	}
+	return stmts, nil
+}
@@ -0,0 +1,235 @@
+package interpreter
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type promiseState int
+
+const (
+	pending promiseState = iota
+	fulfilled
+	rejected
+)
+
+// LoxPromise is returned by a call to async function
+type LoxPromise struct {
+	mu        sync.Mutex
+	name      string
+	state     promiseState
+	value     Value
+	err       error
+	handled   bool
+	callbacks []func()
+}
+
+func (p *LoxPromise) String() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return fmt.Sprintf("<promise %v %v>", p.name, [...]string{"pending", "fulfilled", "rejected"}[p.state])
+}
+
+func (p *LoxPromise) pending() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.state == pending
+}
+
+// rejection returns whether the result was used and the error of the promise
+func (p *LoxPromise) rejection() (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.handled, p.err
+}
+
+func (p *LoxPromise) settle(value Value, err error) {
+	p.mu.Lock()
+	if p.state != pending {
+		p.mu.Unlock()
+		return
+	}
+
+	p.value, p.err, p.state = value, err, fulfilled
+	if err != nil {
+		p.state = rejected
+	}
+	callbacks := p.callbacks
+	p.callbacks = nil
+	p.mu.Unlock()
+
+	for _, c := range callbacks {
+		c()
+	}
+}
+
+// onSettle calls fn once the promise is settled, immediately if it already is
+func (p *LoxPromise) onSettle(fn func()) {
+	p.mu.Lock()
+	if p.state == pending {
+		p.callbacks = append(p.callbacks, fn)
+		p.mu.Unlock()
+		return
+	}
+	p.mu.Unlock()
+	fn()
+}
+
+func (p *LoxPromise) result() (Value, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.handled = true
+	return p.value, p.err
+}
+
+// errLoopClosed stops async functions still waiting when the event loop is closed, it can't be caught by try
+var errLoopClosed = errors.New("event loop is closed")
+
+// asyncTask is a body of async function running on its own goroutine.
+// Like with generators, control is handed off so only one of them runs at a time.
+// A suspended task is unwound when the context is done or the event loop is closed
+type asyncTask struct {
+	loop     *eventLoop
+	resumeCh chan struct{}
+	pausedCh chan struct{}
+}
+
+// startTask runs fn until it's finished or suspended on the first await
+func startTask(loop *eventLoop, fn func(*asyncTask)) {
+	t := &asyncTask{
+		loop:     loop,
+		resumeCh: make(chan struct{}),
+		pausedCh: make(chan struct{}),
+	}
+	go func() {
+		fn(t)
+		t.signal(t.pausedCh)
+	}()
+	t.wait(t.pausedCh)
+}
+
+// signal hands off the control through ch, it returns false when the task is being unwound
+func (t *asyncTask) signal(ch chan struct{}) bool {
+	select {
+	case ch <- struct{}{}:
+		return true
+	case <-t.loop.closed:
+	case <-t.loop.limits.ctx.Done():
+	}
+	return false
+}
+
+// wait takes the control from ch, it returns false when the task is being unwound
+func (t *asyncTask) wait(ch chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	case <-t.loop.closed:
+	case <-t.loop.limits.ctx.Done():
+	}
+	return false
+}
+
+// await suspends the task until the promise is settled, the task is resumed by the event loop
+func (t *asyncTask) await(p *LoxPromise) error {
+	if !p.pending() {
+		return nil
+	}
+
+	p.onSettle(func() {
+		t.loop.schedule(func() error {
+			if t.signal(t.resumeCh) {
+				t.wait(t.pausedCh)
+			}
+			return nil
+		})
+	})
+	if !t.signal(t.pausedCh) || !t.wait(t.resumeCh) {
+		if err := t.loop.limits.contextErr(); err != nil {
+			return err
+		}
+		return errLoopClosed
+	}
+	return nil
+}
+
+func (i *Interpreter) callAsync(name string, fun LoxFunction, scopedEnv *environment) *LoxPromise {
+	promise := &LoxPromise{name: name}
+	env := i.env
+	startTask(i.loop, func(t *asyncTask) {
+		in := i.fork(env)
+		in.task = t
+		v, err := in.callFunction(name, fun, scopedEnv)
+		if err != nil {
+			i.loop.trackRejection(promise)
+		}
+		promise.settle(v, err)
+	})
+	return promise
+}
+
+func (i *Interpreter) await(p *LoxPromise) (Value, error) {
+	if i.task != nil {
+		if err := i.task.await(p); err != nil {
+			return Value{}, err
+		}
+	} else if i.spawned {
+		return Value{}, errors.New("await outside of async function can't be used in spawned goroutine")
+	} else if err := i.loop.run(p); err != nil {
+		return Value{}, err
+	} else if p.pending() {
+		return Value{}, errors.New("awaited promise never settles, event loop is idle")
+	}
+	return p.result()
+}
+
+func timerNatives(i *Interpreter) []nativeFunction {
+	schedule := func(name string, repeat bool) nativeFunction {
+		return nativeFunction{name: name, arity: 2, needs: CapClock, fn: func(args []Value) (Value, error) {
+			ms, ok := getFromValue[int](args[1])
+			if !ok || ms < 0 {
+				return Value{}, fmt.Errorf("%v delay should be a non negative number of milliseconds", name)
+			}
+
+			in := i.fork(i.globals)
+			callback := args[0]
+			id := i.loop.addTimer(time.Duration(ms)*time.Millisecond, repeat, func() error {
+				_, err := in.callObject(name+" callback", callback, []Value{})
+				return err
+			})
+			return toValue(id), nil
+		}}
+	}
+	clear := func(name string) nativeFunction {
+		return nativeFunction{name: name, arity: 1, needs: CapClock, fn: func(args []Value) (Value, error) {
+			id, ok := getFromValue[int](args[0])
+			if !ok {
+				return Value{}, fmt.Errorf("%v expects timer id", name)
+			}
+			i.loop.clearTimer(id)
+			return toValue(nil), nil
+		}}
+	}
+
+	return []nativeFunction{
+		schedule("setTimeout", false),
+		schedule("setInterval", true),
+		clear("clearTimeout"),
+		clear("clearInterval"),
+		{name: "delay", arity: 1, needs: CapClock, fn: func(args []Value) (Value, error) {
+			ms, ok := getFromValue[int](args[0])
+			if !ok || ms < 0 {
+				return Value{}, fmt.Errorf("delay should be a non negative number of milliseconds")
+			}
+
+			promise := &LoxPromise{name: "delay"}
+			i.loop.addTimer(time.Duration(ms)*time.Millisecond, false, func() error {
+				promise.settle(toValue(nil), nil)
+				return nil
+			})
+			return toValue(promise), nil
+		}},
+	}
+}
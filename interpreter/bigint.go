@@ -0,0 +1,96 @@
+package interpreter
+
+import (
+	"fmt"
+	"lox/lexer"
+	"math"
+	"math/big"
+)
+
+// Integers are Go ints as long as they fit, operations which would overflow
+// are done on big.Int. Results of big.Int operations fitting into int are turned back into ints
+
+func addInt(a, b int) (int, bool) {
+	c := a + b
+	return c, (c > a) == (b > 0)
+}
+
+func subInt(a, b int) (int, bool) {
+	c := a - b
+	return c, (c < a) == (b > 0)
+}
+
+func mulInt(a, b int) (int, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	c := a * b
+	overflow := c/b != a || (a == -1 && b == math.MinInt) || (b == -1 && a == math.MinInt)
+	return c, !overflow
+}
+
+func toBigInt(v any) (*big.Int, bool) {
+	if i, ok := canCast[int](&v); ok {
+		return big.NewInt(int64(i)), true
+	} else if b, ok := canCast[*big.Int](&v); ok {
+		return b, true
+	}
+	return nil, false
+}
+
+func normalizeBigInt(b *big.Int) Value {
+	if b.IsInt64() && b.Int64() >= math.MinInt && b.Int64() <= math.MaxInt {
+		return toValue(int(b.Int64()))
+	}
+	return toValue(b)
+}
+
+// maxBigIntBits caps results of multiplication and math.pow, a single
+// operation could exhaust the memory otherwise
+const maxBigIntBits = 1 << 26
+
+// allocateBigInt rejects results estimated to have more than maxBigIntBits
+// and charges their bytes against the allocation limit
+func (l *limits) allocateBigInt(bits int64) error {
+	if bits > maxBigIntBits {
+		return fmt.Errorf("integer of %d bits is too large", bits)
+	}
+	return l.allocate(int(bits / 8))
+}
+
+func bigIntBinary(l *limits, op lexer.Token, left, right *big.Int) (any, error) {
+	switch op.Lexeme {
+	case "+":
+		return normalizeBigInt(new(big.Int).Add(left, right)), nil
+	case "-":
+		return normalizeBigInt(new(big.Int).Sub(left, right)), nil
+	case "*":
+		if err := l.allocateBigInt(int64(left.BitLen() + right.BitLen())); err != nil {
+			return nil, fmt.Errorf("%w, line %v", err, op.Line)
+		}
+		return normalizeBigInt(new(big.Int).Mul(left, right)), nil
+	case "/":
+		if right.Sign() == 0 {
+			return nil, fmt.Errorf("division by zero, line %v", op.Line)
+		}
+		return normalizeBigInt(new(big.Int).Quo(left, right)), nil
+	case "%":
+		if right.Sign() == 0 {
+			return nil, fmt.Errorf("division by zero, line %v", op.Line)
+		}
+		return normalizeBigInt(new(big.Int).Rem(left, right)), nil
+	case ">":
+		return toValue(left.Cmp(right) > 0), nil
+	case ">=":
+		return toValue(left.Cmp(right) >= 0), nil
+	case "<":
+		return toValue(left.Cmp(right) < 0), nil
+	case "<=":
+		return toValue(left.Cmp(right) <= 0), nil
+	case "!=":
+		return toValue(left.Cmp(right) != 0), nil
+	case "==":
+		return toValue(left.Cmp(right) == 0), nil
+	}
+	return nil, fmt.Errorf("unsupported binary operator on int %v, line %v", op, op.Line)
+}
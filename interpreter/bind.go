@@ -0,0 +1,255 @@
+package interpreter
+
+import (
+	"fmt"
+	"lox/typecheck"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// goObject is a pointer to a Go struct exposed to Lox. Exported fields are its
+// properties, named like in Go or by the `lox:"name"` tag, and exported methods can be called
+type goObject struct {
+	ptr reflect.Value
+}
+
+func (o *goObject) String() string {
+	return fmt.Sprintf("<go %v>", o.ptr.Type())
+}
+
+func (o *goObject) typeName() string {
+	return o.ptr.Type().Elem().Name()
+}
+
+var valueType = reflect.TypeOf(Value{})
+
+// Bind exposes a pointer to a Go struct as a global. Its exported fields become
+// readable and writable properties and its exported methods become callable,
+// arguments and results are converted with reflection
+func (i *Interpreter) Bind(name string, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("bind %v: expects non nil pointer to struct, got %T", name, v)
+	}
+	i.globals.create(name, toValue(&goObject{ptr: rv}))
+	i.declare(name, typecheck.Any)
+	return nil
+}
+
+func (o *goObject) field(name string) (reflect.Value, bool) {
+	t := o.ptr.Type().Elem()
+	for j := 0; j < t.NumField(); j++ {
+		f := t.Field(j)
+		if !f.IsExported() {
+			continue
+		}
+		if tag, ok := f.Tag.Lookup("lox"); (ok && tag == name) || (!ok && f.Name == name) {
+			return o.ptr.Elem().Field(j), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func (o *goObject) property(name string) (Value, error) {
+	if f, ok := o.field(name); ok {
+		// nested structs are bound as well, so their fields can be set
+		if f.Kind() == reflect.Struct {
+			return toValue(&goObject{ptr: f.Addr()}), nil
+		}
+		v, err := FromGo(f.Interface())
+		if err != nil {
+			return Value{}, fmt.Errorf("%v.%v: %w", o.typeName(), name, err)
+		}
+		return v, nil
+	} else if m := o.ptr.MethodByName(name); m.IsValid() {
+		return toValue(o.method(name, m)), nil
+	}
+	return Value{}, fmt.Errorf("%v has no field or method %v", o.typeName(), name)
+}
+
+func (o *goObject) setProperty(name string, v Value) error {
+	f, ok := o.field(name)
+	if !ok {
+		return fmt.Errorf("%v has no field %v", o.typeName(), name)
+	}
+	converted, err := fromLox(v, f.Type())
+	if err != nil {
+		return fmt.Errorf("can't set %v.%v: %w", o.typeName(), name, err)
+	}
+	f.Set(converted)
+	return nil
+}
+
+// method wraps the method in a native function. Its results are converted with FromGo,
+// the last result of type error is returned as a Lox error, more than one other result makes a list
+func (o *goObject) method(name string, m reflect.Value) nativeFunction {
+	t := m.Type()
+	fullName := o.typeName() + "." + name
+	arity := t.NumIn()
+	if t.IsVariadic() {
+		arity = Variadic
+	}
+
+	return nativeFunction{name: fullName, arity: arity, fn: func(args []Value) (Value, error) {
+		if t.IsVariadic() && len(args) < t.NumIn()-1 {
+			return Value{}, fmt.Errorf("method %v expects at least %d arguments, got %d", fullName, t.NumIn()-1, len(args))
+		}
+
+		in := []reflect.Value{}
+		for j, arg := range args {
+			var argType reflect.Type
+			if t.IsVariadic() && j >= t.NumIn()-1 {
+				argType = t.In(t.NumIn() - 1).Elem()
+			} else {
+				argType = t.In(j)
+			}
+			converted, err := fromLox(arg, argType)
+			if err != nil {
+				return Value{}, fmt.Errorf("%v argument %d: %w", fullName, j+1, err)
+			}
+			in = append(in, converted)
+		}
+
+		out, err := callMethod(m, in)
+		if err != nil {
+			return Value{}, fmt.Errorf("%v: %w", fullName, err)
+		}
+		if len(out) != 0 && t.Out(len(out)-1) == reflect.TypeOf((*error)(nil)).Elem() {
+			if err, _ := out[len(out)-1].Interface().(error); err != nil {
+				return Value{}, fmt.Errorf("%v: %w", fullName, err)
+			}
+			out = out[:len(out)-1]
+		}
+
+		results := []Value{}
+		for _, r := range out {
+			v, err := FromGo(r.Interface())
+			if err != nil {
+				return Value{}, fmt.Errorf("%v result: %w", fullName, err)
+			}
+			results = append(results, v)
+		}
+		switch len(results) {
+		case 0:
+			return toValue(nil), nil
+		case 1:
+			return results[0], nil
+		}
+		return toValue(&LoxList{elements: results}), nil
+	}}
+}
+
+// callMethod calls the Go method, its panic is returned as an error instead of crashing the host
+func callMethod(m reflect.Value, in []reflect.Value) (out []reflect.Value, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return m.Call(in), nil
+}
+
+// fromLox converts the value to the Go type, failing with an error naming both of them
+func fromLox(v Value, t reflect.Type) (reflect.Value, error) {
+	fail := func() (reflect.Value, error) {
+		return reflect.Value{}, fmt.Errorf("expects %v, got %v", t, describe(v))
+	}
+
+	if t == valueType {
+		return reflect.ValueOf(v), nil
+	} else if obj, ok := getFromValue[*goObject](v); ok {
+		if obj.ptr.Type().AssignableTo(t) {
+			return obj.ptr, nil
+		} else if obj.ptr.Type().Elem().AssignableTo(t) {
+			return obj.ptr.Elem(), nil
+		}
+		return fail()
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		goValue := ToGo(v)
+		if goValue == nil {
+			return reflect.Zero(t), nil
+		} else if !reflect.TypeOf(goValue).AssignableTo(t) {
+			return fail()
+		}
+		return reflect.ValueOf(goValue).Convert(t), nil
+	case reflect.Bool:
+		b, ok := getFromValue[bool](v)
+		if !ok {
+			return fail()
+		}
+		return reflect.ValueOf(b).Convert(t), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := getFromValue[int](v)
+		if !ok || reflect.Zero(t).OverflowInt(int64(n)) {
+			return fail()
+		}
+		return reflect.ValueOf(n).Convert(t), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := getFromValue[int](v)
+		if !ok || n < 0 || reflect.Zero(t).OverflowUint(uint64(n)) {
+			return fail()
+		}
+		return reflect.ValueOf(n).Convert(t), nil
+	case reflect.Float32, reflect.Float64:
+		f, ok := v.AsFloat()
+		if !ok || (t.Kind() == reflect.Float32 && math.Abs(f) > math.MaxFloat32) {
+			return fail()
+		}
+		return reflect.ValueOf(f).Convert(t), nil
+	case reflect.String:
+		s, ok := getFromValue[string](v)
+		if !ok {
+			return fail()
+		}
+		return reflect.ValueOf(s).Convert(t), nil
+	case reflect.Slice:
+		elements, ok := v.AsList()
+		if !ok {
+			return fail()
+		}
+		out := reflect.MakeSlice(t, 0, len(elements))
+		for j, el := range elements {
+			converted, err := fromLox(el, t.Elem())
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("element %d: %w", j, err)
+			}
+			out = reflect.Append(out, converted)
+		}
+		return out, nil
+	case reflect.Map:
+		keys, values, ok := v.AsMap()
+		if !ok {
+			return fail()
+		}
+		out := reflect.MakeMapWithSize(t, len(keys))
+		for j := range keys {
+			key, err := fromLox(keys[j], t.Key())
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("key %v: %w", keys[j], err)
+			}
+			value, err := fromLox(values[j], t.Elem())
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("value of %v: %w", keys[j], err)
+			}
+			out.SetMapIndex(key, value)
+		}
+		return out, nil
+	case reflect.Pointer:
+		if v.IsNil() {
+			return reflect.Zero(t), nil
+		}
+	}
+	return fail()
+}
+
+// describe names the value in conversion errors, strings are quoted
+func describe(v Value) string {
+	if s, ok := v.AsString(); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return strings.TrimSpace(v.String())
+}
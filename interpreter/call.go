@@ -0,0 +1,71 @@
+package interpreter
+
+import (
+	"fmt"
+)
+
+// Function is a handle to a Lox function, native or enum variant, which can be called from Go
+type Function struct {
+	in   *Interpreter
+	name string
+	obj  Value
+}
+
+func (f Function) String() string {
+	return fmt.Sprintf("<function %v>", f.name)
+}
+
+// Function finds a global callable by name
+func (i *Interpreter) Function(name string) (Function, error) {
+	obj, ok := i.globals.get(name)
+	if !ok {
+		return Function{}, fmt.Errorf("can't find function %v", name)
+	} else if obj.Kind() != KindFunction {
+		return Function{}, fmt.Errorf("%v is not a function", name)
+	}
+	return Function{in: i, name: name, obj: obj}, nil
+}
+
+// Call calls the global function with arguments converted by Call of Function
+func (i *Interpreter) Call(name string, args ...any) (any, error) {
+	f, err := i.Function(name)
+	if err != nil {
+		return nil, err
+	}
+	return f.Call(args...)
+}
+
+// Call converts Go arguments with FromGo, Functions are passed as well. The result
+// is converted with ToGo, except functions which are returned as Function handles.
+// Errors raised by Lox code are *StackError
+func (f Function) Call(args ...any) (any, error) {
+	values := []Value{}
+	for j, arg := range args {
+		v, err := FromGo(arg)
+		if err != nil {
+			return nil, fmt.Errorf("argument %d of %v: %w", j+1, f.name, err)
+		}
+		values = append(values, v)
+	}
+
+	ret, err := f.in.callObject(f.name, f.obj, values)
+	if err != nil {
+		return nil, newStackError(err)
+	}
+	retObj, ok := ret.(Value)
+	if !ok {
+		retObj = toValue(ret)
+	}
+	return toGo(retObj, f.in.function), nil
+}
+
+// function converts callables to Function handles for results of Call
+func (i *Interpreter) function(obj Value) any {
+	if native, ok := getFromValue[nativeFunction](obj); ok {
+		return Function{in: i, name: native.name, obj: obj}
+	} else if obj.Kind() == KindFunction {
+		// lox functions are values without names
+		return Function{in: i, name: "anonymous", obj: obj}
+	}
+	return obj
+}
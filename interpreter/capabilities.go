@@ -0,0 +1,101 @@
+package interpreter
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Capability is a set of resources which natives of the standard library may use.
+// Natives needing a capability which wasn't granted fail with an error wrapping os.ErrPermission
+type Capability int
+
+const (
+	// CapFSRead allows readFile, lines and exists
+	CapFSRead Capability = 1 << iota
+	// CapFSWrite allows writeFile and appendFile
+	CapFSWrite
+	// CapEnv allows getEnv
+	CapEnv
+	// CapClock allows clock, now, sleep and timers
+	CapClock
+	// CapStdio allows print and readLine
+	CapStdio
+
+	CapNone Capability = 0
+	CapAll             = CapFSRead | CapFSWrite | CapEnv | CapClock | CapStdio
+)
+
+func (c Capability) String() string {
+	names := []string{}
+	for j, name := range []string{"fs read", "fs write", "env", "clock", "stdio"} {
+		if c&(1<<j) != 0 {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ", ")
+}
+
+// defineNative registers a native of the standard library, natives needing
+// capabilities which weren't granted are replaced by ones failing with a permission error
+func (i *Interpreter) defineNative(native nativeFunction) {
+	if missing := native.needs &^ i.capabilities; missing != 0 {
+		name := native.name
+		native.fn = func([]Value) (Value, error) {
+			return Value{}, fmt.Errorf("%v needs %v capability: %w", name, missing, os.ErrPermission)
+		}
+	}
+	i.globals.create(native.name, toValue(native))
+}
+
+// fsPath maps the path used by the script into the root set with WithFSRoot.
+// Paths can't leave the root with "..", symbolic links are resolved and have to stay inside it
+func (i *Interpreter) fsPath(p string) (string, error) {
+	if i.fsRoot == "" {
+		return p, nil
+	}
+	root, err := filepath.EvalSymlinks(i.fsRoot)
+	if err != nil {
+		return "", err
+	}
+	resolved, err := resolvePath(filepath.Join(root, filepath.FromSlash(path.Clean("/"+filepath.ToSlash(p)))), maxLinks)
+	if err != nil {
+		return "", err
+	}
+	if rel, err := filepath.Rel(root, resolved); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%v is outside of the root: %w", p, os.ErrPermission)
+	}
+	return resolved, nil
+}
+
+// maxLinks limits how many dangling symbolic links resolvePath follows
+const maxLinks = 40
+
+// resolvePath evaluates symbolic links in the path. A file which doesn't exist yet, e.g. one
+// created by writeFile, is resolved by its directory, dangling links by their targets
+func resolvePath(p string, links int) (string, error) {
+	resolved, err := filepath.EvalSymlinks(p)
+	if !errors.Is(err, os.ErrNotExist) {
+		return resolved, err
+	}
+
+	if target, err := os.Readlink(p); err == nil {
+		if links == 0 {
+			return "", fmt.Errorf("too many symbolic links in %v", p)
+		} else if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(p), target)
+		}
+		return resolvePath(target, links-1)
+	}
+	dir, err := filepath.EvalSymlinks(filepath.Dir(p))
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, filepath.Base(p)), nil
+}
@@ -0,0 +1,252 @@
+package interpreter
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var errClosedChannel = errors.New("send on closed channel")
+
+// errDeadlock is returned by channel operations when the main goroutine and all
+// spawned ones wait on channels, so none of them can ever continue. It can't be caught by try
+var errDeadlock = errors.New("all goroutines are asleep - deadlock")
+
+// LoxChannel belongs to the goroutines of the interpreter which created it, their mutex guards it
+type LoxChannel struct {
+	g         *goroutines
+	size      int
+	buffer    []Value
+	closed    bool
+	senders   []chanWait
+	receivers []chanWait
+}
+
+func (c *LoxChannel) String() string {
+	c.g.mu.Lock()
+	defer c.g.mu.Unlock()
+	return fmt.Sprintf("<channel %d/%d>", len(c.buffer), c.size)
+}
+
+// goroutines counts goroutines started by spawn and goroutines blocked on channels. Only the main
+// goroutine runs besides the spawned ones, generators and async functions run in place of their caller
+type goroutines struct {
+	// mu guards channels of the interpreter and waiting goroutines, so a goroutine is
+	// counted as blocked exactly until another one wakes it
+	mu      sync.Mutex
+	spawned int
+	waiters map[*chanWaiter]bool
+	limits  *limits
+}
+
+func newGoroutines(lim *limits) *goroutines {
+	return &goroutines{waiters: map[*chanWaiter]bool{}, limits: lim}
+}
+
+func (g *goroutines) start() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.spawned++
+}
+
+func (g *goroutines) exit() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.spawned--
+	g.checkDeadlock()
+}
+
+// checkDeadlock wakes all waiting goroutines with errDeadlock when the main goroutine waits as well.
+// When the context is done, they are stopped by it instead
+func (g *goroutines) checkDeadlock() {
+	if len(g.waiters) <= g.spawned || g.limits.contextErr() != nil {
+		return
+	}
+	for w := range g.waiters {
+		w.wake(-1, Value{}, errDeadlock)
+	}
+}
+
+// chanWaiter is a goroutine blocked in send, recv or select, the first ready channel wakes it
+type chanWaiter struct {
+	g      *goroutines
+	done   chan struct{}
+	woken  bool
+	chosen int
+	value  Value
+	err    error
+}
+
+func (w *chanWaiter) wake(chosen int, v Value, err error) {
+	w.woken, w.chosen, w.value, w.err = true, chosen, v, err
+	delete(w.g.waiters, w)
+	close(w.done)
+}
+
+// chanWait is a waiter in the queue of a channel, value is the sent one
+type chanWait struct {
+	w     *chanWaiter
+	op    int
+	value Value
+}
+
+// pop returns the first waiter not woken by another channel yet
+func pop(queue *[]chanWait) (chanWait, bool) {
+	for len(*queue) != 0 {
+		first := (*queue)[0]
+		*queue = (*queue)[1:]
+		if !first.w.woken {
+			return first, true
+		}
+	}
+	return chanWait{}, false
+}
+
+// chanOp is a send or recv, a case of select
+type chanOp struct {
+	ch    *LoxChannel
+	send  bool
+	value Value
+}
+
+// try does the operation if it can proceed without waiting
+func (op chanOp) try() (Value, bool, error) {
+	c := op.ch
+	if op.send {
+		if c.closed {
+			return Value{}, true, errClosedChannel
+		} else if r, ok := pop(&c.receivers); ok {
+			r.w.wake(r.op, op.value, nil)
+			return toValue(nil), true, nil
+		} else if len(c.buffer) < c.size {
+			c.buffer = append(c.buffer, op.value)
+			return toValue(nil), true, nil
+		}
+		return Value{}, false, nil
+	}
+
+	if len(c.buffer) != 0 {
+		v := c.buffer[0]
+		c.buffer = c.buffer[1:]
+		if s, ok := pop(&c.senders); ok {
+			c.buffer = append(c.buffer, s.value)
+			s.w.wake(s.op, toValue(nil), nil)
+		}
+		return v, true, nil
+	} else if s, ok := pop(&c.senders); ok {
+		s.w.wake(s.op, toValue(nil), nil)
+		return s.value, true, nil
+	} else if c.closed {
+		// recv returns nil when channel is closed and drained
+		return toValue(nil), true, nil
+	}
+	return Value{}, false, nil
+}
+
+func (c *LoxChannel) close() error {
+	c.g.mu.Lock()
+	defer c.g.mu.Unlock()
+	if c.closed {
+		return errors.New("close of closed channel")
+	}
+
+	c.closed = true
+	for r, ok := pop(&c.receivers); ok; r, ok = pop(&c.receivers) {
+		r.w.wake(r.op, toValue(nil), nil)
+	}
+	for s, ok := pop(&c.senders); ok; s, ok = pop(&c.senders) {
+		s.w.wake(s.op, Value{}, errClosedChannel)
+	}
+	return nil
+}
+
+// selectChannels waits until one of the operations can proceed, the context is done or all goroutines
+// wait, with withDefault it does not block. It returns index of chosen operation (len(ops) for default)
+// and received value. Sending to closed channel is an error, not a panic
+func (i *Interpreter) selectChannels(ops []chanOp, withDefault bool) (int, Value, error) {
+	g := i.goroutines
+	for _, op := range ops {
+		if op.ch.g != g {
+			return -1, Value{}, errors.New("channel of another interpreter can't be used")
+		}
+	}
+
+	g.mu.Lock()
+	for idx, op := range ops {
+		if v, ok, err := op.try(); ok {
+			g.mu.Unlock()
+			return idx, v, err
+		}
+	}
+	if withDefault {
+		g.mu.Unlock()
+		return len(ops), toValue(nil), nil
+	}
+
+	w := &chanWaiter{g: g, done: make(chan struct{})}
+	for idx, op := range ops {
+		if op.send {
+			op.ch.senders = append(op.ch.senders, chanWait{w: w, op: idx, value: op.value})
+		} else {
+			op.ch.receivers = append(op.ch.receivers, chanWait{w: w, op: idx})
+		}
+	}
+	g.waiters[w] = true
+	g.checkDeadlock()
+	g.mu.Unlock()
+
+	select {
+	case <-w.done:
+	case <-i.limits.ctx.Done():
+		g.mu.Lock()
+		// the operation could be done meanwhile, then its result is kept
+		if !w.woken {
+			w.wake(-1, Value{}, i.limits.contextErr())
+		}
+		g.mu.Unlock()
+	}
+	return w.chosen, w.value, w.err
+}
+
+func channelNatives(i *Interpreter) []nativeFunction {
+	return []nativeFunction{
+		{name: "channel", arity: 1, fn: func(args []Value) (Value, error) {
+			size, ok := getFromValue[int](args[0])
+			if !ok || size < 0 {
+				return Value{}, fmt.Errorf("channel size should be a non negative number")
+			}
+			return toValue(&LoxChannel{g: i.goroutines, size: size}), nil
+		}},
+		{name: "send", arity: 2, fn: func(args []Value) (Value, error) {
+			ch, err := toChannel(args[0])
+			if err != nil {
+				return Value{}, err
+			}
+			_, _, err = i.selectChannels([]chanOp{{ch: ch, send: true, value: args[1]}}, false)
+			return toValue(nil), err
+		}},
+		{name: "recv", arity: 1, fn: func(args []Value) (Value, error) {
+			ch, err := toChannel(args[0])
+			if err != nil {
+				return Value{}, err
+			}
+			_, v, err := i.selectChannels([]chanOp{{ch: ch}}, false)
+			return v, err
+		}},
+		{name: "close", arity: 1, fn: func(args []Value) (Value, error) {
+			ch, err := toChannel(args[0])
+			if err != nil {
+				return Value{}, err
+			}
+			return toValue(nil), ch.close()
+		}},
+	}
+}
+
+func toChannel(obj Value) (*LoxChannel, error) {
+	ch, ok := getFromValue[*LoxChannel](obj)
+	if !ok {
+		return nil, fmt.Errorf("%v is not a channel", stringify(*obj.v))
+	}
+	return ch, nil
+}
@@ -1,38 +1,65 @@
 package interpreter
 
-import "fmt"
+import (
+	"fmt"
+	"sync"
+)
 
+// environment is shared between goroutines started with spawn statement,
+// so access to the variables is guarded by the mutex
 type environment struct {
-	d map[string]LoxObject
+	mu        sync.RWMutex
+	d         map[string]Value
 	enclosing *environment
 }
 
 func newEnv() *environment {
 	return &environment{
-		d: map[string]LoxObject{},
+		d: map[string]Value{},
 	}
 }
 
-func (e *environment) put(name string, obj LoxObject) error {
+func (e *environment) put(name string, obj Value) error {
+	e.mu.Lock()
 	_, ok := e.d[name]
 	if ok {
 		e.d[name] = obj
+	}
+	e.mu.Unlock()
+
+	if ok {
 		return nil
 	} else if e.enclosing != nil {
-		e.enclosing.put(name, obj)
-		return nil
+		return e.enclosing.put(name, obj)
 	}
 	return fmt.Errorf("undeclared variable %v", name)
 }
 
-func (e *environment) create(name string, obj LoxObject) {
+func (e *environment) create(name string, obj Value) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 	e.d[name] = obj
 }
 
-func (e *environment) get(name string) (LoxObject, bool) {
+func (e *environment) get(name string) (Value, bool) {
+	e.mu.RLock()
 	v, ok := e.d[name]
+	e.mu.RUnlock()
+
 	if !ok && e.enclosing != nil {
 		return e.enclosing.get(name)
 	}
 	return v, ok
 }
+
+// copy returns variables of this environment, without the enclosing ones
+func (e *environment) copy() map[string]Value {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	out := make(map[string]Value, len(e.d))
+	for name, v := range e.d {
+		out[name] = v
+	}
+	return out
+}
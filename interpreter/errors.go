@@ -0,0 +1,117 @@
+package interpreter
+
+import (
+	"errors"
+	"fmt"
+)
+
+// LoxError is the value bound by catch. Errors with a position in some
+// parsed text, like invalid JSON, have line and column, otherwise they are nil
+type LoxError struct {
+	message string
+	line    Value
+	column  Value
+}
+
+func newLoxError(err error) *LoxError {
+	out := &LoxError{message: err.Error(), line: toValue(nil), column: toValue(nil)}
+	var pos *positionError
+	if errors.As(err, &pos) {
+		out.line, out.column = toValue(pos.line), toValue(pos.column)
+	}
+	return out
+}
+
+func (e *LoxError) property(name string) (Value, error) {
+	switch name {
+	case "message":
+		return toValue(e.message), nil
+	case "line":
+		return e.line, nil
+	case "column":
+		return e.column, nil
+	}
+	return Value{}, fmt.Errorf("error has no property %v", name)
+}
+
+func (e *LoxError) String() string {
+	return e.message
+}
+
+// frameError is an error which left the function, the chain of frameErrors is the Lox stack
+type frameError struct {
+	function string
+	err      error
+}
+
+func (e *frameError) Error() string {
+	return fmt.Sprintf("error during evaluating function %v: %v", e.function, e.err)
+}
+
+func (e *frameError) Unwrap() error {
+	return e.err
+}
+
+// StackError is an error of a Lox function called from Go. Stack holds names
+// of the functions active when it happened, starting with the called one
+type StackError struct {
+	Stack []string
+	Err   error
+}
+
+func newStackError(err error) *StackError {
+	out := &StackError{Err: err}
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if frame, ok := e.(*frameError); ok {
+			out.Stack = append(out.Stack, frame.function)
+		}
+	}
+	return out
+}
+
+func (e *StackError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *StackError) Unwrap() error {
+	return e.Err
+}
+
+// positionError is an error at the given line and column of a parsed text, both start at 1
+type positionError struct {
+	msg    string
+	line   int
+	column int
+}
+
+func (e *positionError) Error() string {
+	return fmt.Sprintf("%v at line %d, column %d", e.msg, e.line, e.column)
+}
+
+// newPositionError finds line and column of the byte offset in the text
+func newPositionError(msg, text string, offset int) *positionError {
+	if offset > len(text) {
+		offset = len(text)
+	} else if offset < 0 {
+		offset = 0
+	}
+	line, column := 1, 1
+	for _, r := range text[:offset] {
+		if r == '\n' {
+			line++
+			column = 1
+		} else {
+			column++
+		}
+	}
+	return &positionError{msg: msg, line: line, column: column}
+}
+
+// catchable errors are the ones try statement can handle, not break or return unwinding the stack,
+// not exceeded limits, a deadlock, a misplaced yield or a closed event loop
+func catchable(err error) bool {
+	var ret returnSignal
+	var limit *LimitExceeded
+	return !errors.Is(err, errBreak) && !errors.Is(err, errGeneratorClosed) && !errors.Is(err, errDeadlock) &&
+		!errors.Is(err, errYieldOutside) && !errors.Is(err, errLoopClosed) && !errors.As(err, &ret) && !errors.As(err, &limit)
+}
@@ -0,0 +1,60 @@
+package interpreter
+
+import (
+	"fmt"
+	"lox/compiler"
+	"lox/parser"
+	"lox/typecheck"
+)
+
+// Parse lexes, parses and type checks the source, the error is compiler.Diagnostics
+func Parse(source string) ([]parser.Statement, error) {
+	return parse(source, typecheck.NewChecker())
+}
+
+// parse checks the source with the checker, which knows declarations of sources checked before
+func parse(source string, checker *typecheck.Checker) ([]parser.Statement, error) {
+	stmts, diagnostics := compiler.Compile("eval", source, checker)
+	if diagnostics != nil {
+		return nil, diagnostics
+	}
+	return stmts, nil
+}
+
+// Eval runs the source in the interpreter and returns the value of its last
+// expression statement, nil when there is none. The event loop runs after the statements.
+// The source is type checked together with the sources evaluated before,
+// problems found before running are returned as compiler.Diagnostics
+func (i *Interpreter) Eval(source string) (Value, error) {
+	stmts, err := parse(source, i.typeChecker())
+	if err != nil {
+		return Value{}, err
+	}
+	return i.Run(stmts)
+}
+
+// Run runs parsed statements like Eval
+func (i *Interpreter) Run(stmts []parser.Statement) (Value, error) {
+	result := toValue(nil)
+	for _, stmt := range stmts {
+		if err := i.limits.step(); err != nil {
+			return Value{}, err
+		}
+		expr, ok := stmt.(parser.StatementExpression)
+		if !ok {
+			if err := stmt.AcceptStatement(i); err != nil {
+				return Value{}, err
+			}
+			continue
+		}
+
+		v, err := expr.Expression.AcceptExpr(i)
+		if err != nil {
+			return Value{}, err
+		}
+		if result, ok = v.(Value); !ok {
+			return Value{}, fmt.Errorf("unknown type of expression result")
+		}
+	}
+	return result, i.RunEventLoop()
+}
@@ -0,0 +1,198 @@
+package interpreter
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+type Clock interface {
+	Now() time.Time
+	Sleep(time.Duration)
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+// VirtualClock does not wait, sleeping just moves the time forward.
+// Scripts scheduling timers run instantly and deterministically
+type VirtualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func NewVirtualClock(start time.Time) *VirtualClock {
+	return &VirtualClock{now: start}
+}
+
+func (v *VirtualClock) Now() time.Time {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.now
+}
+
+func (v *VirtualClock) Sleep(d time.Duration) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.now = v.now.Add(d)
+}
+
+type timer struct {
+	id       int
+	when     time.Time
+	interval time.Duration
+	repeat   bool
+	fn       func() error
+}
+
+// eventLoop runs callbacks of timers and continuations of async functions.
+// Tasks are run one at a time, in the order they were scheduled
+type eventLoop struct {
+	mu        sync.Mutex
+	clock     Clock
+	tasks     []func() error
+	timers    []*timer
+	nextTimer int
+	rejected  []*LoxPromise
+	limits    *limits
+	// closed unwinds async functions still waiting when the loop won't run anymore
+	closed   chan struct{}
+	isClosed bool
+}
+
+func newEventLoop(lim *limits) *eventLoop {
+	return &eventLoop{clock: realClock{}, limits: lim, closed: make(chan struct{})}
+}
+
+func (l *eventLoop) close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.isClosed {
+		l.isClosed = true
+		close(l.closed)
+	}
+}
+
+func (l *eventLoop) schedule(task func() error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tasks = append(l.tasks, task)
+}
+
+func (l *eventLoop) addTimer(delay time.Duration, repeat bool, fn func() error) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.nextTimer++
+	l.insertTimer(&timer{
+		id:       l.nextTimer,
+		when:     l.clock.Now().Add(delay),
+		interval: delay,
+		repeat:   repeat,
+		fn:       fn,
+	})
+	return l.nextTimer
+}
+
+// insertTimer keeps timers sorted by time, timers with the same time keep the order of scheduling
+func (l *eventLoop) insertTimer(t *timer) {
+	idx := sort.Search(len(l.timers), func(j int) bool {
+		return l.timers[j].when.After(t.when)
+	})
+	l.timers = append(l.timers, nil)
+	copy(l.timers[idx+1:], l.timers[idx:])
+	l.timers[idx] = t
+}
+
+func (l *eventLoop) clearTimer(id int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for j, t := range l.timers {
+		if t.id == id {
+			l.timers = append(l.timers[:j], l.timers[j+1:]...)
+			return
+		}
+	}
+}
+
+// nextTask returns the next task to run, waiting for the closest timer if needed.
+// It returns false when the loop is idle
+func (l *eventLoop) nextTask() (func() error, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for {
+		if len(l.tasks) != 0 {
+			task := l.tasks[0]
+			l.tasks = l.tasks[1:]
+			return task, true
+		} else if len(l.timers) == 0 {
+			return nil, false
+		}
+
+		t := l.timers[0]
+		wait := t.when.Sub(l.clock.Now())
+		if wait <= 0 {
+			l.timers = l.timers[1:]
+			if t.repeat {
+				t.when = t.when.Add(t.interval)
+				l.insertTimer(t)
+			}
+			return t.fn, true
+		}
+
+		// the timer stays queued while sleeping, it can be cleared or an earlier one added meanwhile
+		l.mu.Unlock()
+		err := l.limits.sleep(l.clock, wait)
+		l.mu.Lock()
+		if err != nil {
+			return func() error { return err }, true
+		}
+	}
+}
+
+// run executes tasks until the loop is idle or until the promise is settled
+func (l *eventLoop) run(until *LoxPromise) error {
+	for until == nil || until.pending() {
+		task, ok := l.nextTask()
+		if !ok {
+			break
+		}
+		if err := task(); err != nil {
+			return err
+		}
+	}
+
+	if until == nil {
+		return l.unhandledRejection()
+	}
+	return nil
+}
+
+func (l *eventLoop) trackRejection(p *LoxPromise) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rejected = append(l.rejected, p)
+}
+
+func (l *eventLoop) unhandledRejection() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, p := range l.rejected {
+		if handled, err := p.rejection(); !handled {
+			return fmt.Errorf("unhandled promise rejection: %w", err)
+		}
+	}
+	l.rejected = nil
+	return nil
+}
@@ -0,0 +1,64 @@
+package interpreter
+
+import (
+	"fmt"
+	"lox/lexer"
+	"math"
+	"math/big"
+)
+
+// Numbers with a fraction are float64. Operations mixing integers
+// and floats are done on floats
+
+func toFloat(v any) (float64, bool) {
+	if f, ok := canCast[float64](&v); ok {
+		return f, true
+	} else if b, ok := toBigInt(v); ok {
+		f, _ := new(big.Float).SetInt(b).Float64()
+		return f, true
+	}
+	return 0, false
+}
+
+// floatToInt turns integral float into an integer, big one if it doesn't fit into int
+func floatToInt(f float64) (Value, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Value{}, fmt.Errorf("can't convert %v to integer", f)
+	}
+	b, _ := big.NewFloat(f).Int(nil)
+	return normalizeBigInt(b), nil
+}
+
+func floatBinary(op lexer.Token, left, right float64) (any, error) {
+	switch op.Lexeme {
+	case "+":
+		return toValue(left + right), nil
+	case "-":
+		return toValue(left - right), nil
+	case "*":
+		return toValue(left * right), nil
+	case "/":
+		if right == 0 {
+			return nil, fmt.Errorf("division by zero, line %v", op.Line)
+		}
+		return toValue(left / right), nil
+	case "%":
+		if right == 0 {
+			return nil, fmt.Errorf("division by zero, line %v", op.Line)
+		}
+		return toValue(math.Mod(left, right)), nil
+	case ">":
+		return toValue(left > right), nil
+	case ">=":
+		return toValue(left >= right), nil
+	case "<":
+		return toValue(left < right), nil
+	case "<=":
+		return toValue(left <= right), nil
+	case "!=":
+		return toValue(left != right), nil
+	case "==":
+		return toValue(left == right), nil
+	}
+	return nil, fmt.Errorf("unsupported binary operator on float %v, line %v", op, op.Line)
+}
@@ -0,0 +1,197 @@
+package interpreter
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var errGeneratorClosed = errors.New("generator closed")
+
+// errYieldOutside is a misplaced yield, it can't be caught by try
+var errYieldOutside = errors.New("yield outside of generator function")
+
+type generatorStep struct {
+	value Value
+	done  bool
+	err   error
+}
+
+// LoxGenerator is returned by a call to function containing yield.
+// Body of the function runs on its own goroutine, which hands off the control
+// back and forth with the caller, so only one of them runs at a time.
+// Callers from different goroutines are serialized by the mutex, a call to next
+// from the body of the generator, even through other generators, is an error.
+// Suspended goroutine stops when the generator is closed or the context of the interpreter is done
+type LoxGenerator struct {
+	mu      sync.Mutex
+	name    string
+	run     func(*LoxGenerator) error
+	limits  *limits
+	resume  chan struct{}
+	stop    chan struct{}
+	steps   chan generatorStep
+	started bool
+	done    bool
+
+	// caller is the generator whose body resumed this one, nil for other callers
+	callerMu sync.Mutex
+	running  bool
+	caller   *LoxGenerator
+}
+
+func newGenerator(name string, lim *limits, run func(*LoxGenerator) error) *LoxGenerator {
+	return &LoxGenerator{
+		name:   name,
+		run:    run,
+		limits: lim,
+		resume: make(chan struct{}),
+		stop:   make(chan struct{}),
+		// the last step never blocks, so the goroutine can finish when nobody waits for it
+		steps: make(chan generatorStep, 1),
+	}
+}
+
+func (g *LoxGenerator) String() string {
+	return fmt.Sprintf("<generator %v>", g.name)
+}
+
+// runsIn reports whether the body of the generator is running and waits for the caller,
+// which is the generator whose body calls, nil outside of generators
+func (g *LoxGenerator) runsIn(caller *LoxGenerator) bool {
+	for c := caller; c != nil; c = c.resumedBy() {
+		if c == g {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *LoxGenerator) resumedBy() *LoxGenerator {
+	g.callerMu.Lock()
+	defer g.callerMu.Unlock()
+	if !g.running {
+		return nil
+	}
+	return g.caller
+}
+
+func (g *LoxGenerator) setRunning(running bool, caller *LoxGenerator) {
+	g.callerMu.Lock()
+	defer g.callerMu.Unlock()
+	g.running, g.caller = running, caller
+}
+
+// next resumes the generator until the next yield. It returns false when generator is exhausted
+func (g *LoxGenerator) next(caller *LoxGenerator) (Value, bool, error) {
+	if g.runsIn(caller) {
+		return Value{}, false, fmt.Errorf("generator %v is already running", g.name)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.done {
+		return Value{}, false, nil
+	}
+
+	g.setRunning(true, caller)
+	defer g.setRunning(false, nil)
+
+	var step generatorStep
+	if !g.started {
+		g.started = true
+		go g.start()
+		step = <-g.steps
+	} else {
+		select {
+		case g.resume <- struct{}{}:
+			step = <-g.steps
+		case step = <-g.steps:
+			// suspended goroutine stopped when the context was done
+		}
+	}
+
+	if step.done {
+		g.done = true
+		return Value{}, false, step.err
+	}
+	return step.value, true, nil
+}
+
+// close stops suspended generator and waits until its goroutine is finished
+func (g *LoxGenerator) close(caller *LoxGenerator) error {
+	if g.runsIn(caller) {
+		return fmt.Errorf("generator %v can't be closed while it's running", g.name)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.done {
+		return nil
+	}
+
+	g.done = true
+	if g.started {
+		close(g.stop)
+		<-g.steps
+	}
+	return nil
+}
+
+func (g *LoxGenerator) start() {
+	err := g.run(g)
+	var ret returnSignal
+	if errors.Is(err, errGeneratorClosed) || errors.As(err, &ret) {
+		err = nil
+	}
+	g.steps <- generatorStep{done: true, err: err}
+}
+
+// yield is called from the generator goroutine, it suspends it until next value is requested
+func (g *LoxGenerator) yield(v Value) error {
+	g.steps <- generatorStep{value: v}
+	select {
+	case <-g.resume:
+		return nil
+	case <-g.stop:
+		return errGeneratorClosed
+	case <-g.limits.ctx.Done():
+		return g.limits.contextErr()
+	}
+}
+
+// generatorIterator walks over the generator for the code running in the body of caller
+type generatorIterator struct {
+	g      *LoxGenerator
+	caller *LoxGenerator
+}
+
+func (it generatorIterator) next() (Value, bool, error) {
+	return it.g.next(it.caller)
+}
+
+func (it generatorIterator) close() {
+	// running generator iterating over itself fails on next, there's nothing to close
+	_ = it.g.close(it.caller)
+}
+
+// property returns methods of the generator called from the body of caller
+func (g *LoxGenerator) property(caller *LoxGenerator, name string) (Value, error) {
+	switch name {
+	case "next":
+		return toValue(nativeFunction{name: g.name + ".next", fn: func([]Value) (Value, error) {
+			v, ok, err := g.next(caller)
+			if err != nil || !ok {
+				return toValue(nil), err
+			}
+			return v, nil
+		}}), nil
+	case "close":
+		return toValue(nativeFunction{name: g.name + ".close", fn: func([]Value) (Value, error) {
+			return toValue(nil), g.close(caller)
+		}}), nil
+	}
+	return Value{}, fmt.Errorf("generator has no property %v", name)
+}
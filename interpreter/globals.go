@@ -0,0 +1,27 @@
+package interpreter
+
+import (
+	"fmt"
+	"lox/typecheck"
+)
+
+// SetGlobal defines or replaces a global variable, the value is converted with FromGo
+func (i *Interpreter) SetGlobal(name string, value any) error {
+	v, err := FromGo(value)
+	if err != nil {
+		return fmt.Errorf("global %v: %w", name, err)
+	}
+	i.globals.create(name, v)
+	i.declare(name, typecheck.Any)
+	return nil
+}
+
+// GetGlobal returns the global variable, false when it isn't defined
+func (i *Interpreter) GetGlobal(name string) (Value, bool) {
+	return i.globals.get(name)
+}
+
+// Globals returns a copy of global variables, including natives and modules of the standard library
+func (i *Interpreter) Globals() map[string]Value {
+	return i.globals.copy()
+}
@@ -1,10 +1,19 @@
 package interpreter
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io"
+	"lox/compiler"
 	"lox/lexer"
 	"lox/parser"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -14,112 +23,112 @@ func TestInterpretExpression(t *testing.T) {
 	testCases := []struct {
 		desc     string
 		input    string
-		expected LoxObject
+		expected Value
 	}{
 		{
 			desc:     "simple numeric literal",
 			input:    "15;",
-			expected: toLoxObj(15),
+			expected: toValue(15),
 		},
 		{
 			desc:     "string literal",
 			input:    "\"hello\";",
-			expected: toLoxObj("hello"),
+			expected: toValue("hello"),
 		},
 		{
 			desc:     "bool literal",
 			input:    "true;",
-			expected: toLoxObj(true),
+			expected: toValue(true),
 		},
 		{
 			desc:     "simple expression1",
 			input:    "15+5;",
-			expected: toLoxObj(20),
+			expected: toValue(20),
 		},
 		{
 			desc:     "simple expression2",
 			input:    "15-5;",
-			expected: toLoxObj(10),
+			expected: toValue(10),
 		},
 		{
 			desc:     "simple expression3",
 			input:    "5-17;",
-			expected: toLoxObj(-12),
+			expected: toValue(-12),
 		},
 		{
 			desc:     "simple expression4",
 			input:    "3*5;",
-			expected: toLoxObj(15),
+			expected: toValue(15),
 		},
 		{
 			desc:     "simple expression5",
 			input:    "15/5;",
-			expected: toLoxObj(3),
+			expected: toValue(3),
 		},
 		{
 			desc:     "unary expr1",
 			input:    "-15;",
-			expected: toLoxObj(-15),
+			expected: toValue(-15),
 		},
 		{
 			desc:     "unary expr2",
 			input:    "!false;",
-			expected: toLoxObj(true),
+			expected: toValue(true),
 		},
 		{
 			desc:     "complicated expr",
 			input:    "5*3+1;",
-			expected: toLoxObj(16),
+			expected: toValue(16),
 		},
 		{
 			desc:     "complicated expr2",
 			input:    "1+5*3;",
-			expected: toLoxObj(16),
+			expected: toValue(16),
 		},
 		{
 			desc:     "complicated expr3",
 			input:    "(1+5)*3 + 2;",
-			expected: toLoxObj(20),
+			expected: toValue(20),
 		},
 		{
 			desc:     "complicated expr4",
 			input:    "1+5*3 + 2;",
-			expected: toLoxObj(18),
+			expected: toValue(18),
 		},
 		{
 			desc:     "boolean expr",
 			input:    "5*3+1 == 16;",
-			expected: toLoxObj(true),
+			expected: toValue(true),
 		},
 		{
 			desc:     "boolean expr2",
 			input:    "true == !true;",
-			expected: toLoxObj(false),
+			expected: toValue(false),
 		},
 		{
 			desc:     "boolean expr3",
 			input:    "!false != !true;",
-			expected: toLoxObj(true),
+			expected: toValue(true),
 		},
 		{
 			desc:     "string concantenation and comparison",
 			input:    `"foo" + "bar" == "foobar";`,
-			expected: toLoxObj(true),
+			expected: toValue(true),
 		},
 		{
 			desc:     "string concantenation",
 			input:    `"foo" + "bar";`,
-			expected: toLoxObj("foobar"),
+			expected: toValue("foobar"),
 		},
 		{
 			desc:     "modulo",
 			input:    `15 % 3;`,
-			expected: toLoxObj(15 % 3),
+			expected: toValue(15 % 3),
 		},
 		{
 			desc:     "modulo 2",
 			input:    `15 % 2;`,
-			expected: toLoxObj(15 % 2),
+			expected: toValue(15 % 2),
 		},
 	}
 	for _, tC := range testCases {
@@ -177,7 +186,7 @@ func TestInterpreterWithVariables(t *testing.T) {
 		5 + x;`
 		stmts := parseIt(t, input)
 		assert.Equal(t, []parser.Statement{
-			parser.LetStatement{parser.AssignmentStatement{"x", parser.Literal(lexer.Token{lexer.Number, "4", 1})}},
+			parser.LetStatement{AssignmentStatement: parser.AssignmentStatement{"x", parser.Literal(lexer.Token{lexer.Number, "4", 1})}},
 			parser.StatementExpression{parser.Binary{
 				Op:    lexer.Token{lexer.Operator, "+", 2},
 				Left:  parser.Literal(lexer.Token{lexer.Number, "5", 2}),
@@ -192,65 +201,65 @@ func TestInterpreter(t *testing.T) {
 	testCases := []struct {
 		desc     string
 		input    string
-		expected LoxObject
+		expected Value
 	}{
 		{
 			desc:     "simple number declaration",
 			input:    `let result = 14;`,
-			expected: toLoxObj(14),
+			expected: toValue(14),
 		},
 		{
 			desc:     "simple string declaration",
 			input:    `let result = "foobar";`,
-			expected: toLoxObj("foobar"),
+			expected: toValue("foobar"),
 		},
 		{
 			desc:     "arithmetic and boolean",
 			input:    `let x = 123;
 			let y = 5;
 			let result = (x + y) == 128;`,
-			expected: toLoxObj(true),
+			expected: toValue(true),
 		},
 		{
 			desc:     "arithmetic and boolean 2",
 			input:    `let x = 123;
 			let y = 6;
 			let result = (x + y) == 128;`,
-			expected: toLoxObj(false),
+			expected: toValue(false),
 		},
 		{
 			desc:     "arithmetic and boolean 3",
 			input:    `let x = 5;
 			let y = 6;
 			let result = (x + y)*3-1 == 32;`,
-			expected: toLoxObj(true),
+			expected: toValue(true),
 		},
 		{
 			desc:     "arithmetic and boolean 4",
 			input:    `let x = 5;
 			let y = 6;
 			let result = (x + y)*3-1 + 12;`,
-			expected: toLoxObj(44),
+			expected: toValue(44),
 		},
 		{
 			desc:     "assignment",
 			input:    `let result = 3;
 			result = result + 4;`,
-			expected: toLoxObj(7),
+			expected: toValue(7),
 		},
 		{
 			desc:     "if",
 			input:    `let result = 0;
 			let x = 1;
 			if (x == 1) {result = 1; }`,
-			expected: toLoxObj(1),
+			expected: toValue(1),
 		},
 		{
 			desc:     "if not executed",
 			input:    `let result = -1;
 			let x = 0;
 			if (x == 1) {result = 1; }`,
-			expected: toLoxObj(-1),
+			expected: toValue(-1),
 		},
 		{
 			desc:     "else",
@@ -258,7 +267,7 @@ func TestInterpreter(t *testing.T) {
 			let x = 0;
 			if (x == 1) {result = 1; }
 			else {result=2;}`,
-			expected: toLoxObj(2),
+			expected: toValue(2),
 		},
 		{
 			desc:     "if else",
@@ -267,41 +276,41 @@ func TestInterpreter(t *testing.T) {
 			if (x == 1) {result = 1; }
 			else if (x == 2) {result = 2;}
 			else { result=3; }`,
-			expected: toLoxObj(2),
+			expected: toValue(2),
 		},
 		{
 			desc:     "if with logic operators",
 			input:    `let result = 0;
 			let x = 2;
 			if (x == 1 || true) {result = 1; }`,
-			expected: toLoxObj(1),
+			expected: toValue(1),
 		},
 		{
 			desc:     "if with logic operators",
 			input:    `let result = 0;
 			let x = 2;
 			if (x == 2 && !false) {result = 1; }`,
-			expected: toLoxObj(1),
+			expected: toValue(1),
 		},
 		{
 			desc:     "if with logic operators",
 			input:    `let result = -2;
 			let x = 2;
 			if (x == 2 && false) {result = 1; }`,
-			expected: toLoxObj(-2),
+			expected: toValue(-2),
 		},
 		{
 			desc:     "if with logic more operators",
 			input:    `let result = 0;
 			if (2 == 1+1 && true && (13 > 2 && 3 >= 3)) {result = 1; }`,
-			expected: toLoxObj(1),
+			expected: toValue(1),
 		},
 		{
 			desc:     "if with logic more operators",
 			input:    `let result = 0;
 			let i = 15;
 			if ((i % 3 == 0) && (i % 5 == 0)) {result = 1; }`,
-			expected: toLoxObj(1),
+			expected: toValue(1),
 		},
 		{
 			desc:     "while",
@@ -311,7 +320,7 @@ func TestInterpreter(t *testing.T) {
 				result = result + i;
 				i = i + 1;
 			}`,
-			expected: toLoxObj(10),
+			expected: toValue(10),
 		},
 		{
 			desc:     "function",
@@ -320,7 +329,7 @@ func TestInterpreter(t *testing.T) {
 				result = 1;
 			}
 			foo();`,
-			expected: toLoxObj(1),
+			expected: toValue(1),
 		},
 		{
 			desc:     "function with arg",
@@ -329,7 +338,7 @@ func TestInterpreter(t *testing.T) {
 				result = a;
 			}
 			foo(12);`,
-			expected: toLoxObj(12),
+			expected: toValue(12),
 		},
 		{
 			desc:     "function with args",
@@ -338,7 +347,7 @@ func TestInterpreter(t *testing.T) {
 				result = a + b;
 			}
 			foo(12, 3+2);`,
-			expected: toLoxObj(17),
+			expected: toValue(17),
 		},
 		{
 			desc:     "function double called",
@@ -348,7 +357,7 @@ func TestInterpreter(t *testing.T) {
 			let result = 0;
 			foo(2);
 			foo(3);`,
-			expected: toLoxObj(5),
+			expected: toValue(5),
 		},
 		{
 			desc:     "blocked while",
@@ -360,7 +369,7 @@ func TestInterpreter(t *testing.T) {
 					i = i + 1;
 				}
 			}`,
-			expected: toLoxObj(6),
+			expected: toValue(6),
 		},
 		{
 			desc:     "fibonacci",
@@ -385,13 +394,13 @@ func TestInterpreter(t *testing.T) {
 			}
 			let result = 0;
 			fibo(8);`,
-			expected: toLoxObj(21),
+			expected: toValue(21),
 		},
 		{
 			desc:     "std lib call",
 			input:    `let result = 0;
 			print("foo");`,
-			expected: toLoxObj(0),
+			expected: toValue(0),
 		},
 	}
 	for _, tC := range testCases {
@@ -419,19 +428,2777 @@ func TestScoping(t *testing.T) {
 
 		execute(t, in, statements)
 
-		assertVariable(t, toLoxObj(124), "foo", in)
+		assertVariable(t, toValue(124), "foo", in)
 		assertVariableNotFound(t, "bar", in)
 	})
 }
 
+func TestEnums(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		input    string
+		expected Value
+	}{
+		{
+			desc: "variants have identity equality",
+			input: `enum Color { Red, Green, Blue }
+			let c = Color.Green;
+			let result = (c == Color.Green) && (c != Color.Red);`,
+			expected: toValue(true),
+		},
+		{
+			desc: "variants with payload are distinct values",
+			input: `enum Shape { Circle(r), Rect(w, h) }
+			let result = Shape.Circle(1) == Shape.Circle(1);`,
+			expected: toValue(false),
+		},
+		{
+			desc: "payload fields",
+			input: `enum Shape { Circle(r), Rect(w, h) }
+			let s = Shape.Rect(2, 3);
+			let result = s.w * s.h;`,
+			expected: toValue(6),
+		},
+		{
+			desc: "match with bindings",
+			input: `enum Shape { Circle(r), Rect(w, h) }
+			let result = 0;
+			let s = Shape.Rect(2, 3);
+			match (s) {
+				case Circle(r) { result = 3 * r * r; }
+				case Rect(w, h) { result = w * h; }
+			}`,
+			expected: toValue(6),
+		},
+		{
+			desc: "match with else",
+			input: `enum Color { Red, Green, Blue }
+			let result = "none";
+			match (Color.Blue) {
+				case Red { result = "red"; }
+				else { result = "other"; }
+			}`,
+			expected: toValue("other"),
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			statements := parseIt(t, tC.input)
+			in := NewInterpreter()
+
+			execute(t, in, statements)
+
+			assertVariable(t, tC.expected, "result", in)
+		})
+	}
+
+	t.Run("printing", func(t *testing.T) {
+		statements := parseIt(t, `enum Shape { Circle(r), Rect(w, h) }
+		let s = Shape.Rect(2, 3);`)
+		in := NewInterpreter()
+
+		execute(t, in, statements)
+
+		v, ok := in.GetGlobal("s")
+		require.True(t, ok)
+		assert.Equal(t, "Shape.Rect(2, 3)", fmt.Sprint(*v.v))
+	})
+
+	invalidCases := []struct {
+		desc  string
+		input string
+	}{
+		{
+			desc: "non exhaustive match",
+			input: `enum Color { Red, Green, Blue }
+			match (Color.Red) {
+				case Red { }
+				case Green { }
+			}`,
+		},
+		{
+			desc: "unknown variant",
+			input: `enum Color { Red, Green, Blue }
+			let c = Color.Rde;`,
+		},
+		{
+			desc: "unknown variant in match",
+			input: `enum Color { Red, Green, Blue }
+			match (Color.Red) {
+				case Rde { }
+				else { }
+			}`,
+		},
+		{
+			desc: "invalid payload size",
+			input: `enum Shape { Circle(r), Rect(w, h) }
+			let s = Shape.Rect(2);`,
+		},
+	}
+	for _, tC := range invalidCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			assert.Error(t, Interpret(parseIt(t, tC.input)))
+		})
+	}
+}
+
+func TestOperatorOverloading(t *testing.T) {
+	vec := `enum Vec {
+				V(x, y)
+				function __add__(self, other) { return Vec.V(self.x + other.x, self.y + other.y); }
+				function __mul__(self, k) { return Vec.V(self.x * k, self.y * k); }
+				function __neg__(self) { return Vec.V(-self.x, -self.y); }
+				function __eq__(self, other) { return (self.x == other.x) && (self.y == other.y); }
+				function __lt__(self, other) { return self.len() < other.len(); }
+				function __index__(self, i) { return [self.x, self.y][i]; }
+				function len(self) { return self.x * self.x + self.y * self.y; }
+			}
+			`
+	testCases := []struct {
+		desc     string
+		input    string
+		expected Value
+	}{
+		{
+			desc:     "arithmetic",
+			input:    `let v = -(Vec.V(1, 2) + Vec.V(3, 4)) * 2; let result = [v.x, v.y];`,
+			expected: toValue(&LoxList{elements: []Value{toValue(-8), toValue(-12)}}),
+		},
+		{
+			desc:     "equality",
+			input:    `let result = [Vec.V(1, 2) == Vec.V(1, 2), Vec.V(1, 2) != Vec.V(1, 2), Vec.V(1, 2) != Vec.V(2, 1)];`,
+			expected: toValue(&LoxList{elements: []Value{toValue(true), toValue(false), toValue(true)}}),
+		},
+		{
+			desc:     "comparison is reflected on the right operand",
+			input:    `let result = [Vec.V(1, 1) < Vec.V(2, 2), Vec.V(3, 3) > Vec.V(2, 2)];`,
+			expected: toValue(&LoxList{elements: []Value{toValue(true), toValue(true)}}),
+		},
+		{
+			desc:     "index",
+			input:    `let v = Vec.V(5, 6); let result = v[0] + v[1];`,
+			expected: toValue(11),
+		},
+		{
+			desc:     "method call",
+			input:    `let result = Vec.V(3, 4).len();`,
+			expected: toValue(25),
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			statements := parseIt(t, vec+tC.input)
+			in := NewInterpreter()
+
+			execute(t, in, statements)
+
+			assertVariable(t, tC.expected, "result", in)
+		})
+	}
+
+	t.Run("list index", func(t *testing.T) {
+		statements := parseIt(t, `let xs = [1, [2, 3]]; let result = xs[1][0];`)
+		in := NewInterpreter()
+
+		execute(t, in, statements)
+
+		assertVariable(t, toValue(2), "result", in)
+	})
+
+	invalidCases := []struct {
+		desc  string
+		input string
+	}{
+		{
+			desc:  "operator without method",
+			input: vec + `let v = Vec.V(1, 2) - Vec.V(1, 2);`,
+		},
+		{
+			desc:  "method arity",
+			input: vec + `let v = Vec.V(1, 2).len(1);`,
+		},
+		{
+			desc:  "list index out of range",
+			input: `let xs = [1]; let v = xs[1];`,
+		},
+		{
+			desc:  "method without parameters",
+			input: `enum Color { Red function foo() { } }`,
+		},
+	}
+	for _, tC := range invalidCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			assert.Error(t, Interpret(parseIt(t, tC.input)))
+		})
+	}
+}
+
+func TestClasses(t *testing.T) {
+	point := `class Point {
+				function init(self, x, y) { self.x = x; self.y = y; }
+				function __add__(self, other) { return Point(self.x + other.x, self.y + other.y); }
+				function __neg__(self) { return Point(-self.x, -self.y); }
+				function __eq__(self, other) { return (self.x == other.x) && (self.y == other.y); }
+				function __lt__(self, other) { return self.len() < other.len(); }
+				function __index__(self, i) { return [self.x, self.y][i]; }
+				function len(self) { return self.x * self.x + self.y * self.y; }
+			}
+			`
+	list := func(values ...any) Value {
+		elements := []Value{}
+		for _, v := range values {
+			elements = append(elements, toValue(v))
+		}
+		return toValue(&LoxList{elements: elements})
+	}
+	testCases := []struct {
+		desc     string
+		input    string
+		expected Value
+	}{
+		{
+			desc:     "fields and methods",
+			input:    `let p = Point(3, 4); p.x = 6; p.y = 8; let result = [p.x, p.len()];`,
+			expected: list(6, 100),
+		},
+		{
+			desc:     "arithmetic",
+			input:    `let p = -(Point(1, 2) + Point(3, 4)); let result = [p.x, p.y];`,
+			expected: list(-4, -6),
+		},
+		{
+			desc:     "equality",
+			input:    `let result = [Point(1, 2) == Point(1, 2), Point(1, 2) != Point(1, 2), Point(1, 2) != Point(2, 1)];`,
+			expected: list(true, false, true),
+		},
+		{
+			desc:     "comparison is reflected on the right operand",
+			input:    `let result = [Point(1, 1) < Point(2, 2), Point(3, 3) > Point(2, 2)];`,
+			expected: list(true, true),
+		},
+		{
+			desc:     "index",
+			input:    `let p = Point(5, 6); let result = p[0] + p[1];`,
+			expected: toValue(11),
+		},
+		{
+			desc:     "membership uses __eq__",
+			input:    `let result = [Point(1, 2) in [Point(0, 0), Point(1, 2)], Point(2, 1) in [Point(1, 2)]];`,
+			expected: list(true, false),
+		},
+		{
+			desc:     "map keys use __eq__",
+			input:    `let m = {}; m[Point(1, 2)] = "a"; m[Point(1, 2)] = "b"; let result = [len(m), m[Point(1, 2)]];`,
+			expected: list(1, "b"),
+		},
+		{
+			desc:     "instances without __eq__ are equal to themselves",
+			input:    `class Box { } let b = Box(); let result = [b == b, b == Box(), b != Box()];`,
+			expected: list(true, false, true),
+		},
+		{
+			desc: "iterable instance",
+			input: `class Countdown {
+				function init(self, n) { self.n = n; }
+				function next(self) {
+					if (self.n == 0) { return nil; }
+					self.n = self.n - 1;
+					return self.n;
+				}
+			}
+			let result = 0;
+			for (x in Countdown(4)) {
+				result = result + x;
+			}`,
+			expected: toValue(6),
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			statements := parseIt(t, point+tC.input)
+			in := NewInterpreter()
+
+			execute(t, in, statements)
+
+			assertVariable(t, tC.expected, "result", in)
+		})
+	}
+
+	invalidCases := []struct {
+		desc  string
+		input string
+	}{
+		{
+			desc:  "operator without method",
+			input: point + `let p = Point(1, 2) - Point(1, 2);`,
+		},
+		{
+			desc:  "missing field",
+			input: point + `let z = Point(1, 2).z;`,
+		},
+		{
+			desc:  "arguments of init",
+			input: point + `let p = Point(1);`,
+		},
+		{
+			desc:  "arguments without init",
+			input: `class Box { } let b = Box(1);`,
+		},
+		{
+			desc:  "method without parameters",
+			input: `class Box { function foo() { } }`,
+		},
+		{
+			desc:  "duplicated method",
+			input: `class Box { function foo(self) { } function foo(self) { } }`,
+		},
+	}
+	for _, tC := range invalidCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			assert.Error(t, Interpret(parseIt(t, tC.input)))
+		})
+	}
+
+	t.Run("property of value without fields", func(t *testing.T) {
+		_, err := NewInterpreter().Eval(`let n = 1;
+		n.x = 2;`)
+		assert.EqualError(t, err, "can't set property x of 1, line 2")
+	})
+}
+
+func TestRanges(t *testing.T) {
+	list := func(values ...any) Value {
+		elements := []Value{}
+		for _, v := range values {
+			elements = append(elements, toValue(v))
+		}
+		return toValue(&LoxList{elements: elements})
+	}
+	testCases := []struct {
+		desc     string
+		input    string
+		expected Value
+	}{
+		{
+			desc:     "inclusive range",
+			input:    `let result = 0; for (x in 1..10) { result = result + x; }`,
+			expected: toValue(55),
+		},
+		{
+			desc:     "exclusive range",
+			input:    `let result = 9; for (x in 0..<5) { result = result * 10 + x; }`,
+			expected: toValue(901234),
+		},
+		{
+			desc:     "step",
+			input:    `let result = 0; for (x in (0..10).step(3)) { result = result * 10 + x; }`,
+			expected: toValue(369),
+		},
+		{
+			desc:     "negative step",
+			input:    `let result = 0; for (x in (5..1).step(-2)) { result = result * 10 + x; } for (x in (3..<0).step(-1)) { result = result * 10 + x; }`,
+			expected: toValue(531321),
+		},
+		{
+			desc:     "empty range",
+			input:    `let result = 0; for (x in 5..1) { result = result + 1; }`,
+			expected: toValue(0),
+		},
+		{
+			desc:     "membership",
+			input:    `let result = [3 in 1..5, 5 in 1..<5, 4 in (0..10).step(2), 5 in (0..10).step(2), "b" in ["a", "b"], "ell" in "hello", 1 in []];`,
+			expected: list(true, false, true, false, true, true, false),
+		},
+		{
+			desc: "membership honors __eq__",
+			input: `enum P { P(x, y) function __eq__(self, other) { return self.x == other.x; } }
+			let result = P.P(1, 5) in [P.P(2, 2), P.P(1, 2)];`,
+			expected: toValue(true),
+		},
+		{
+			desc:     "slicing",
+			input:    `let xs = [1, 2, 3, 4, 5]; let result = [xs[1..3], xs[(4..0).step(-2)], "hello"[1..<4], xs[0..<0]];`,
+			expected: list(*list(2, 3, 4).v, *list(5, 3, 1).v, "ell", *list().v),
+		},
+		{
+			desc: "iter method returning iterable",
+			input: `enum Bag { B(items) function iter(self) { return self.items; } }
+			let result = 0;
+			for (x in Bag.B([1, 2, 3])) { result = result * 10 + x; }`,
+			expected: toValue(123),
+		},
+		{
+			desc: "iter method as generator",
+			input: `enum Count { C(n)
+				function iter(self) {
+					let k = 1;
+					while (k <= self.n) { yield k; k = k + 1; }
+				}
+			}
+			let result = [0 in Count.C(3), 3 in Count.C(3)];`,
+			expected: list(false, true),
+		},
+		{
+			desc: "next method",
+			input: `enum Drain { D(ch) function next(self) { return recv(self.ch); } }
+			let ch = channel(3);
+			send(ch, 1); send(ch, 2); close(ch);
+			let result = 0;
+			for (x in Drain.D(ch)) { result = result * 10 + x; }`,
+			expected: toValue(12),
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			statements := parseIt(t, tC.input)
+			in := NewInterpreter()
+
+			execute(t, in, statements)
+
+			assertVariable(t, tC.expected, "result", in)
+		})
+	}
+
+	t.Run("printing", func(t *testing.T) {
+		assert.Equal(t, "1..10", fmt.Sprint(&LoxRange{start: 1, end: 10, step: 1, inclusive: true}))
+		assert.Equal(t, "(0..<10).step(2)", fmt.Sprint(&LoxRange{start: 0, end: 10, step: 2}))
+	})
+
+	invalidCases := []struct {
+		desc  string
+		input string
+	}{
+		{
+			desc:  "zero step",
+			input: `let r = (1..3).step(0);`,
+		},
+		{
+			desc:  "slice out of range",
+			input: `let xs = [1, 2][1..2];`,
+		},
+		{
+			desc:  "non integer bounds",
+			input: `let r = 1.."a";`,
+		},
+		{
+			desc:  "membership in number",
+			input: `let r = 1 in 5;`,
+		},
+	}
+	for _, tC := range invalidCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			assert.Error(t, Interpret(parseIt(t, tC.input)))
+		})
+	}
+}
+
+func TestStrings(t *testing.T) {
+	list := func(values ...any) Value {
+		elements := []Value{}
+		for _, v := range values {
+			elements = append(elements, toValue(v))
+		}
+		return toValue(&LoxList{elements: elements})
+	}
+	testCases := []struct {
+		desc     string
+		input    string
+		expected Value
+	}{
+		{
+			desc:     "len counts characters",
+			input:    `let result = [len("zażółć"), len(""), len([1, 2]), len(0..<5)];`,
+			expected: list(6, 0, 2, 5),
+		},
+		{
+			desc:     "substr and indexOf",
+			input:    `let s = "zażółć gęślą"; let result = [substr(s, 2, 4), indexOf(s, "gę"), indexOf(s, "x")];`,
+			expected: list("żółć", 7, -1),
+		},
+		{
+			desc:     "split and join",
+			input:    `let parts = split("a,b,,c", ","); let result = [len(parts), join(parts, "-"), join([1, nil, true], "")];`,
+			expected: list(4, "a-b--c", "1niltrue"),
+		},
+		{
+			desc:     "case and whitespace",
+			input:    `let result = [trim("  ab c 	"), upper("ąb"), lower("ĄB")];`,
+			expected: list("ab c", "ĄB", "ąb"),
+		},
+		{
+			desc:     "replace, startsWith and repeat",
+			input:    `let result = [replace("a-b-c", "-", "+"), startsWith("hello", "he"), startsWith("hello", "lo"), repeat("ab", 3), repeat("x", 0)];`,
+			expected: list("a+b+c", true, false, "ababab", ""),
+		},
+		{
+			desc:     "chars",
+			input:    `let result = chars("日本");`,
+			expected: list("日", "本"),
+		},
+		{
+			desc:     "conversions",
+			input:    `let result = [str(12) + str(nil), num("42") + 1, num(" -7 "), str(num("99999999999999999999")), str([1, "a"])];`,
+			expected: list("12nil", 43, -7, "99999999999999999999", "[1, a]"),
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			statements := parseIt(t, tC.input)
+			in := NewInterpreter()
+
+			execute(t, in, statements)
+
+			assertVariable(t, tC.expected, "result", in)
+		})
+	}
+
+	invalidCases := []struct {
+		desc  string
+		input string
+	}{
+		{
+			desc:  "wrong argument type",
+			input: `let s = upper(1);`,
+		},
+		{
+			desc:  "substr out of range",
+			input: `let s = substr("abc", 2, 2);`,
+		},
+		{
+			desc:  "negative repeat",
+			input: `let s = repeat("a", -1);`,
+		},
+		{
+			desc:  "num of invalid string",
+			input: `let n = num("12a");`,
+		},
+		{
+			desc:  "join of non list",
+			input: `let s = join("abc", ",");`,
+		},
+	}
+	for _, tC := range invalidCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			assert.Error(t, Interpret(parseIt(t, tC.input)))
+		})
+	}
+}
+
+func TestMath(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		input    string
+		expected Value
+	}{
+		{
+			desc:     "float arithmetic",
+			input:    `let result = (1.5 + 2) * 2 - 0.5 / 0.25;`,
+			expected: toValue(5.0),
+		},
+		{
+			desc:     "mixed comparison",
+			input:    `let result = (1 == 1.0) && (2 > 1.5) && (-0.5 < 0);`,
+			expected: toValue(true),
+		},
+		{
+			desc:     "sqrt and pow",
+			input:    `let result = math.sqrt(16) + math.pow(2, 3) + math.pow(4, 0.5);`,
+			expected: toValue(14.0),
+		},
+		{
+			desc:     "integer pow is exact",
+			input:    `let result = str(math.pow(3, 50));`,
+			expected: toValue("717897987691852588770249"),
+		},
+		{
+			desc:     "rounding returns integers",
+			input:    `let result = [math.floor(-2.5), math.ceil(2.1), math.round(2.5), math.abs(-3), math.abs(-1.5)];`,
+			expected: toValue(&LoxList{elements: []Value{toValue(-3), toValue(3), toValue(3), toValue(3), toValue(1.5)}}),
+		},
+		{
+			desc:     "min and max",
+			input:    `let result = [math.min(2, 1.5), math.max(2, 1.5), math.min(-1, 3)];`,
+			expected: toValue(&LoxList{elements: []Value{toValue(1.5), toValue(2), toValue(-1)}}),
+		},
+		{
+			desc:     "trigonometry",
+			input:    `let result = math.round(math.sin(math.pi / 2) * 100 + math.cos(0) + math.atan2(1, 1) * 4 / math.pi);`,
+			expected: toValue(102),
+		},
+		{
+			desc:     "gcd and lcm",
+			input:    `let result = [math.gcd(12, -18), math.lcm(4, 6), math.gcd(0, 0)];`,
+			expected: toValue(&LoxList{elements: []Value{toValue(6), toValue(12), toValue(0)}}),
+		},
+		{
+			desc:     "num parses floats",
+			input:    `let result = num("2.5") * 2;`,
+			expected: toValue(5.0),
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			statements := parseIt(t, tC.input)
+			in := NewInterpreter()
+
+			execute(t, in, statements)
+
+			assertVariable(t, tC.expected, "result", in)
+		})
+	}
+
+	t.Run("seeded random is reproducible", func(t *testing.T) {
+		program := `random.seed(7);
+		let result = "";
+		let k = 0;
+		while (k < 20) {
+			let roll = random.randInt(1, 6);
+			if ((roll < 1) || (roll > 6)) { roll = nil; }
+			result = result + str(roll) + random.choice(["a", "b", "c"]);
+			k = k + 1;
+		}`
+		results := []string{}
+		for j := 0; j < 2; j++ {
+			in := NewInterpreter()
+			execute(t, in, parseIt(t, program))
+
+			v, ok := in.GetGlobal("result")
+			require.True(t, ok)
+			results = append(results, stringify(*v.v))
+		}
+		assert.Equal(t, results[0], results[1])
+		assert.NotContains(t, results[0], "nil")
+	})
+
+	invalidCases := []struct {
+		desc  string
+		input string
+	}{
+		{
+			desc:  "sqrt of negative",
+			input: `let x = math.sqrt(-1);`,
+		},
+		{
+			desc:  "unknown member",
+			input: `let x = math.foo;`,
+		},
+		{
+			desc:  "wrong argument type",
+			input: `let x = math.sin("a");`,
+		},
+		{
+			desc:  "empty randInt range",
+			input: `let x = random.randInt(3, 1);`,
+		},
+		{
+			desc:  "choice from empty list",
+			input: `let x = random.choice([]);`,
+		},
+		{
+			desc:  "float division by zero",
+			input: `let x = 1.5 / 0;`,
+		},
+	}
+	for _, tC := range invalidCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			assert.Error(t, Interpret(parseIt(t, tC.input)))
+		})
+	}
+}
+
+func TestIO(t *testing.T) {
+	t.Run("files", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "notes.txt")
+		statements := parseIt(t, fmt.Sprintf(`let path = %q;
+		let before = exists(path);
+		writeFile(path, "first
+second");
+		appendFile(path, "
+zażółć
+");
+		let content = readFile(path);
+		let result = [];
+		for (line in lines(path)) {
+			result = [result, line];
+		}
+		let after = exists(path);`, path))
+		in := NewInterpreter()
+
+		execute(t, in, statements)
+
+		assertVariable(t, toValue(false), "before", in)
+		assertVariable(t, toValue(true), "after", in)
+		assertVariable(t, toValue("first\nsecond\nzażółć\n"), "content", in)
+		v, ok := in.GetGlobal("result")
+		require.True(t, ok)
+		assert.Equal(t, "[[[[], first], second], zażółć]", stringify(*v.v))
+	})
+
+	t.Run("break closes lines", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "notes.txt")
+		require.NoError(t, os.WriteFile(path, []byte("a\nb\nc"), 0644))
+		statements := parseIt(t, fmt.Sprintf(`let result = "";
+		for (line in lines(%q)) {
+			result = result + line;
+			if (line == "b") { break; }
+		}`, path))
+		in := NewInterpreter()
+
+		execute(t, in, statements)
+
+		assertVariable(t, toValue("ab"), "result", in)
+	})
+
+	t.Run("readLine", func(t *testing.T) {
+		statements := parseIt(t, `let a = readLine(); let b = readLine(); let c = readLine();`)
+		in := NewInterpreter(WithStdin(strings.NewReader("first\r\nlast")))
+
+		execute(t, in, statements)
+
+		assertVariable(t, toValue("first"), "a", in)
+		assertVariable(t, toValue("last"), "b", in)
+		assertVariable(t, toValue(nil), "c", in)
+	})
+
+	t.Run("output", func(t *testing.T) {
+		statements := parseIt(t, `
+function fail() { return 1 + nil; }
+print("hello");
+print([1, "a"]);
+spawn fail();
+`)
+		var stdout bytes.Buffer
+		stderr := make(chanWriter, 1)
+		in := NewInterpreter(WithStdout(&stdout), WithStderr(stderr))
+
+		execute(t, in, statements)
+
+		assert.Equal(t, "hello\n[1, a]\n", stdout.String())
+		select {
+		case msg := <-stderr:
+			assert.Contains(t, msg, "error in spawned function fail")
+		case <-time.After(time.Second):
+			t.Fatal("spawned function error not written")
+		}
+	})
+
+	t.Run("interpreters don't share output", func(t *testing.T) {
+		var first, second bytes.Buffer
+		a, b := NewInterpreter(WithStdout(&first)), NewInterpreter(WithStdout(&second))
+		_, err := a.Eval(`print("a");`)
+		require.NoError(t, err)
+		_, err = b.Eval(`print("b");`)
+		require.NoError(t, err)
+		assert.Equal(t, "a\n", first.String())
+		assert.Equal(t, "b\n", second.String())
+	})
+
+	missing := filepath.Join(t.TempDir(), "missing", "file.txt")
+	invalidCases := []struct {
+		desc  string
+		input string
+	}{
+		{
+			desc:  "reading missing file",
+			input: fmt.Sprintf(`let s = readFile(%q);`, missing),
+		},
+		{
+			desc:  "writing into missing directory",
+			input: fmt.Sprintf(`writeFile(%q, "a");`, missing),
+		},
+		{
+			desc:  "iterating missing file",
+			input: fmt.Sprintf(`for (line in lines(%q)) { }`, missing),
+		},
+		{
+			desc:  "non string content",
+			input: fmt.Sprintf(`appendFile(%q, 1);`, missing),
+		},
+	}
+	for _, tC := range invalidCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			assert.Error(t, Interpret(parseIt(t, tC.input)))
+		})
+	}
+}
+
+func TestMaps(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		input    string
+		expected string
+	}{
+		{
+			desc:     "literal keeps insertion order",
+			input:    `let result = {"b": 1, "a": [2], 3: nil};`,
+			expected: "{b: 1, a: [2], 3: nil}",
+		},
+		{
+			desc:     "index and property access",
+			input:    `let m = {"name": "lox", 1: "one"}; let result = [m["name"], m.name, m[1], m[1.0], m["missing"]];`,
+			expected: "[lox, lox, one, one, nil]",
+		},
+		{
+			desc:     "index assignment",
+			input:    `let m = {"a": 1}; m["a"] = 2; m["b"] = 3; let xs = [1, 2]; xs[1] = m; let result = xs;`,
+			expected: "[1, {a: 2, b: 3}]",
+		},
+		{
+			desc:     "membership, len and iteration",
+			input:    `let m = {"a": 1, "b": 2}; let result = [("a" in m), ("c" in m), len(m)]; for (k in m) { result[0] = k; }`,
+			expected: "[b, false, 2]",
+		},
+		{
+			desc:     "keys, values and remove",
+			input:    `let m = {"a": 1, "b": 2, "c": 3}; let removed = remove(m, "b"); let result = [keys(m), values(m), removed, remove(m, "x"), m["c"]];`,
+			expected: "[[a, c], [1, 3], 2, nil, 3]",
+		},
+		{
+			desc: "keys compared with __eq__",
+			input: `enum P { P(x, y) function __eq__(self, other) { return self.x == other.x; } }
+			let m = {P.P(1, 1): "first"};
+			m[P.P(1, 2)] = "second";
+			let result = [len(m), m[P.P(1, 3)]];`,
+			expected: "[1, second]",
+		},
+		{
+			desc:     "object destructuring of map",
+			input:    `let {name, age} = {"age": 3, "name": "lox"}; let result = [name, age];`,
+			expected: "[lox, 3]",
+		},
+		{
+			desc:     "containers nested in themselves",
+			input:    `let a = [1]; a[0] = a; let m = {"self": nil, "list": a}; m["self"] = m; let result = [a, m];`,
+			expected: "[[[...]], {self: {...}, list: [[...]]}]",
+		},
+		{
+			desc:     "container repeated without a cycle",
+			input:    `let a = [1]; let result = [a, {"x": a, "y": a}];`,
+			expected: "[[1], {x: [1], y: [1]}]",
+		},
+		{
+			desc:     "cyclic containers are compared by identity",
+			input:    `let a = [1]; a[0] = a; let m = {"x": 0}; m[a] = 1; let result = [(a in a), m[a], ([a] in a)];`,
+			expected: "[true, 1, false]",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			statements := parseIt(t, tC.input)
+			in := NewInterpreter()
+
+			execute(t, in, statements)
+
+			v, ok := in.GetGlobal("result")
+			require.True(t, ok)
+			assert.Equal(t, tC.expected, stringify(*v.v))
+		})
+	}
+
+	invalidCases := []struct {
+		desc  string
+		input string
+	}{
+		{
+			desc:  "missing property",
+			input: `let v = {"a": 1}.b;`,
+		},
+		{
+			desc:  "list index assignment out of range",
+			input: `let xs = [1]; xs[1] = 2;`,
+		},
+		{
+			desc:  "index assignment on string",
+			input: `let s = "abc"; s[0] = "d";`,
+		},
+	}
+	for _, tC := range invalidCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			assert.Error(t, Interpret(parseIt(t, tC.input)))
+		})
+	}
+}
+
+func TestTryCatch(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		input    string
+		expected Value
+	}{
+		{
+			desc:     "no error",
+			input:    `let result = 1; try { result = 2; } catch (e) { result = 3; }`,
+			expected: toValue(2),
+		},
+		{
+			desc:     "runtime error",
+			input:    `let result = 1; try { let x = 1 / 0; result = 2; } catch (e) { result = e.message; }`,
+			expected: toValue("division by zero, line 1"),
+		},
+		{
+			desc: "error from nested function",
+			input: `function fail() { let x = upper(1); }
+			let result = nil;
+			try { fail(); } catch (e) { result = e.line; }`,
+			expected: toValue(nil),
+		},
+		{
+			desc: "break and return are not caught",
+			input: `function find() {
+				while (true) {
+					try { break; } catch (e) { return "caught"; }
+				}
+				try { return "returned"; } catch (e) { }
+				return "end";
+			}
+			let result = find();`,
+			expected: toValue("returned"),
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			statements := parseIt(t, tC.input)
+			in := NewInterpreter()
+
+			execute(t, in, statements)
+
+			assertVariable(t, tC.expected, "result", in)
+		})
+	}
+
+	t.Run("error in catch block", func(t *testing.T) {
+		assert.Error(t, Interpret(parseIt(t, `try { let x = 1 / 0; } catch (e) { let y = e.foo; }`)))
+	})
+}
+
+func TestJSON(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		input    string
+		expected Value
+	}{
+		{
+			desc:     "parse",
+			input:    `let v = json.parse("{\"b\": [1, 2.5, true, null, {}], \"a\": \"zażółć\", \"big\": 12345678901234567890}"); let result = str(v);`,
+			expected: toValue("{b: [1, 2.5, true, nil, {}], a: zażółć, big: 12345678901234567890}"),
+		},
+		{
+			desc:     "stringify compact",
+			input:    `let result = json.stringify({"a": [1, 2.5, nil], "b": "say \"hi\" <3", "c": {}}, 0);`,
+			expected: toValue(`{"a":[1,2.5,null],"b":"say \"hi\" <3","c":{}}`),
+		},
+		{
+			desc:  "stringify with indent",
+			input: `let result = json.stringify({"a": [1], "b": []}, 2);`,
+			expected: toValue(`{
+  "a": [
+    1
+  ],
+  "b": []
+}`),
+		},
+		{
+			desc:     "round trip",
+			input:    `let text = "{\"k\":[{\"x\":-1},false]}"; let result = json.stringify(json.parse(text), 0) == text;`,
+			expected: toValue(true),
+		},
+		{
+			desc: "parse error position",
+			input: `let result = nil;
+			try {
+				json.parse("{\"a\": 1,
+				  \"b\": x}");
+			} catch (e) {
+				result = [e.line, e.column];
+			}`,
+			expected: toValue(&LoxList{elements: []Value{toValue(2), toValue(12)}}),
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			statements := parseIt(t, tC.input)
+			in := NewInterpreter()
+
+			execute(t, in, statements)
+
+			assertVariable(t, tC.expected, "result", in)
+		})
+	}
+
+	invalidCases := []struct {
+		desc  string
+		input string
+	}{
+		{
+			desc:  "unexpected end",
+			input: `let v = json.parse("[1, 2");`,
+		},
+		{
+			desc:  "data after value",
+			input: `let v = json.parse("[1] 2");`,
+		},
+		{
+			desc:  "non string keys",
+			input: `let v = json.stringify({1: 2}, 0);`,
+		},
+		{
+			desc:  "cyclic list",
+			input: `let xs = [1]; xs[0] = xs; let v = json.stringify(xs, 0);`,
+		},
+		{
+			desc:  "function value",
+			input: `let v = json.stringify([print], 0);`,
+		},
+	}
+	for _, tC := range invalidCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			assert.Error(t, Interpret(parseIt(t, tC.input)))
+		})
+	}
+}
+
+func TestTime(t *testing.T) {
+	// 2024-03-10 12:30:00 UTC
+	start := time.Date(2024, 3, 10, 12, 30, 0, 0, time.UTC)
+	testCases := []struct {
+		desc     string
+		input    string
+		expected Value
+	}{
+		{
+			desc:     "now and sleep",
+			input:    `let before = now(); sleep(1500); let result = now() - before;`,
+			expected: toValue(1500),
+		},
+		{
+			desc:     "clock measures seconds",
+			input:    `let before = clock(); sleep(250); let result = clock() - before;`,
+			expected: toValue(0.25),
+		},
+		{
+			desc:     "format in time zones",
+			input:    `let t = now(); let result = [formatDate(t, "2006-01-02 15:04 MST", "UTC"), formatDate(t, "Jan 2 15:04 -07:00", "America/New_York"), formatDate(t, "15:04", "Asia/Tokyo")];`,
+			expected: toValue(&LoxList{elements: []Value{toValue("2024-03-10 12:30 UTC"), toValue("Mar 10 08:30 -04:00"), toValue("21:30")}}),
+		},
+		{
+			desc:     "parse",
+			input:    `let result = [parseDate("2024-03-10 13:30", "2006-01-02 15:04", "Europe/Warsaw") == now(), parseDate("2024-03-10T12:30:00Z", "2006-01-02T15:04:05Z07:00", "Asia/Tokyo") == now()];`,
+			expected: toValue(&LoxList{elements: []Value{toValue(true), toValue(true)}}),
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			statements := parseIt(t, tC.input)
+			in := NewInterpreter(WithClock(NewVirtualClock(start)))
+
+			execute(t, in, statements)
+
+			assertVariable(t, tC.expected, "result", in)
+		})
+	}
+
+	invalidCases := []struct {
+		desc  string
+		input string
+	}{
+		{
+			desc:  "unknown time zone",
+			input: `let s = formatDate(0, "2006", "Mars/Olympus");`,
+		},
+		{
+			desc:  "text not matching layout",
+			input: `let t = parseDate("10/03/2024", "2006-01-02", "UTC");`,
+		},
+		{
+			desc:  "negative sleep",
+			input: `sleep(-1);`,
+		},
+	}
+	for _, tC := range invalidCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			assert.Error(t, Interpret(parseIt(t, tC.input)))
+		})
+	}
+}
+
+func TestDefine(t *testing.T) {
+	define := func(in *Interpreter) {
+		in.Define("add", 2, func(args []Value) (Value, error) {
+			a, err := IntArg("add", args[0])
+			if err != nil {
+				return Value{}, err
+			}
+			b, err := IntArg("add", args[1])
+			if err != nil {
+				return Value{}, err
+			}
+			return NewInt(a + b), nil
+		})
+		in.Define("concat", Variadic, func(args []Value) (Value, error) {
+			out := ""
+			for _, arg := range args {
+				s, err := StringArg("concat", arg)
+				if err != nil {
+					return Value{}, err
+				}
+				out += s
+			}
+			return NewString(out), nil
+		})
+		in.Define("pair", 1, func(args []Value) (Value, error) {
+			return NewList(args[0], args[0]), nil
+		})
+		in.Define("noop", 0, func(args []Value) (Value, error) {
+			return Value{}, nil
+		})
+	}
+
+	testCases := []struct {
+		desc     string
+		input    string
+		expected Value
+	}{
+		{
+			desc:     "returns a value",
+			input:    `let result = add(2, 3) * 2;`,
+			expected: toValue(10),
+		},
+		{
+			desc:     "variadic",
+			input:    `let result = [concat(), concat("a"), concat("a", "b", "c")];`,
+			expected: toValue(&LoxList{elements: []Value{toValue(""), toValue("a"), toValue("abc")}}),
+		},
+		{
+			desc:     "passed as a value",
+			input:    `function apply(f, x) { return f(x, x); } let f = add; let result = apply(f, 4);`,
+			expected: toValue(8),
+		},
+		{
+			desc:     "list result",
+			input:    `let result = len(pair(1));`,
+			expected: toValue(2),
+		},
+		{
+			desc:     "error can be caught",
+			input:    `let result = nil; try { concat("a", 1); } catch (e) { result = e.message; }`,
+			expected: toValue("concat expects string, got 1"),
+		},
+		{
+			desc:     "zero value is nil",
+			input:    `let result = noop();`,
+			expected: toValue(nil),
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			statements := parseIt(t, tC.input)
+			in := NewInterpreter()
+			define(in)
+
+			execute(t, in, statements)
+
+			assertVariable(t, tC.expected, "result", in)
+		})
+	}
+
+	invalidCases := []struct {
+		desc  string
+		input string
+	}{
+		{
+			desc:  "wrong number of arguments",
+			input: `let x = add(1);`,
+		},
+		{
+			desc:  "wrong argument type",
+			input: `let x = add(1, "2");`,
+		},
+	}
+	for _, tC := range invalidCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			in := NewInterpreter()
+			define(in)
+			var err error
+			for _, st := range parseIt(t, tC.input) {
+				if err = st.AcceptStatement(in); err != nil {
+					break
+				}
+			}
+			assert.Error(t, err)
+		})
+	}
+
+	t.Run("overrides signatures of natives", func(t *testing.T) {
+		in := NewInterpreter()
+		in.Define("len", 2, func(args []Value) (Value, error) {
+			return NewInt(len(args)), nil
+		})
+		in.Define("upper", Variadic, func(args []Value) (Value, error) {
+			return NewInt(len(args)), nil
+		})
+
+		got, err := in.Eval(`len(1, 2) + upper("a", "b", "c");`)
+		require.NoError(t, err)
+		assert.Equal(t, NewInt(5), got)
+
+		in.Define("lower", Variadic, func(args []Value) (Value, error) {
+			return NewInt(len(args)), nil
+		})
+		got, err = in.Eval(`lower(1, 2);`)
+		require.NoError(t, err, "defined after the checker is created")
+		assert.Equal(t, NewInt(2), got)
+	})
+}
+
+func TestCall(t *testing.T) {
+	source := `
+function fibo(n) {
+	if (n < 2) { return n; }
+	return fibo(n - 1) + fibo(n - 2);
+}
+function describe(user) { return user["name"] + " " + str(len(user["tags"])); }
+function doubles(xs) { let out = {}; for (x in xs) { out[x] = x * 2; } return out; }
+function apply(f, x) { return f(x); }
+function adder(n) { return add; }
+function add(x) { return x + 1; }
+function inner(x) { return x + nil; }
+function outer(x) { return inner(x); }
+let notFunction = 1;
+`
+	in := NewInterpreter()
+	execute(t, in, parseIt(t, source))
+
+	got, err := in.Call("fibo", 8)
+	require.NoError(t, err)
+	assert.Equal(t, 21, got)
+
+	got, err = in.Call("describe", map[string]any{"name": "ada", "tags": []string{"a", "b"}})
+	require.NoError(t, err)
+	assert.Equal(t, "ada 2", got)
+
+	got, err = in.Call("doubles", []int{1, 2})
+	require.NoError(t, err)
+	assert.Equal(t, map[any]any{1: 2, 2: 4}, got)
+
+	got, err = in.Call("add", uint64(1)<<63)
+	require.NoError(t, err)
+	expected, _ := new(big.Int).SetString("9223372036854775809", 10)
+	assert.Equal(t, expected, got)
+
+	upper, err := in.Function("upper")
+	require.NoError(t, err)
+	got, err = in.Call("apply", upper, "lox")
+	require.NoError(t, err)
+	assert.Equal(t, "LOX", got)
+
+	got, err = in.Call("adder", 1)
+	require.NoError(t, err)
+	f, ok := got.(Function)
+	require.True(t, ok)
+	got, err = f.Call(41)
+	require.NoError(t, err)
+	assert.Equal(t, 42, got)
+
+	_, err = in.Call("outer", 1)
+	var stackErr *StackError
+	require.ErrorAs(t, err, &stackErr)
+	assert.Equal(t, []string{"outer", "inner"}, stackErr.Stack)
+
+	_, err = in.Call("notFunction")
+	assert.Error(t, err)
+	_, err = in.Call("missing")
+	assert.Error(t, err)
+	_, err = in.Call("fibo", struct{}{})
+	assert.Error(t, err)
+}
+
+func TestEval(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		input    string
+		expected string
+	}{
+		{
+			desc:     "expression",
+			input:    `1 + 2;`,
+			expected: "3",
+		},
+		{
+			desc:     "last expression statement",
+			input:    `let x = [1, 2]; x; len(x) * 10; let y = 1;`,
+			expected: "20",
+		},
+		{
+			desc:     "no expression",
+			input:    `let x = 1;`,
+			expected: "nil",
+		},
+		{
+			desc:     "value is taken before timers run",
+			input:    `let x = 1; function set() { x = 2; } setTimeout(set, 0); x;`,
+			expected: "1",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			got, err := NewInterpreter().Eval(tC.input)
+			require.NoError(t, err)
+			assert.Equal(t, tC.expected, got.String())
+		})
+	}
+
+	t.Run("state is kept between calls", func(t *testing.T) {
+		in := NewInterpreter()
+		_, err := in.Eval(`let x = 40;`)
+		require.NoError(t, err)
+		got, err := in.Eval(`x + 2;`)
+		require.NoError(t, err)
+		assert.Equal(t, toValue(42), got)
+		assert.True(t, Value{}.IsNil())
+	})
+
+	t.Run("types are kept between calls", func(t *testing.T) {
+		in := NewInterpreter()
+		_, err := in.Eval(`let y: number = 1; function half(n: number): number { return n / 2; }`)
+		require.NoError(t, err)
+		_, err = in.Eval(`y = "s";`)
+		assert.ErrorContains(t, err, "can't assign string to y of type number")
+		_, err = in.Eval(`half("a");`)
+		assert.ErrorContains(t, err, "argument 1 of function half should be number, got string")
+
+		// declarations of a source with type errors are forgotten, as it doesn't run
+		_, err = in.Eval(`let z: string = "s"; let w: number = "a";`)
+		require.Error(t, err)
+		_, err = in.Eval(`function f(): number { return z; }`)
+		assert.NoError(t, err)
+	})
+
+	for _, input := range []string{`let x = ;`, `1 + nil;`, `let x: number = "a";`} {
+		_, err := NewInterpreter().Eval(input)
+		assert.Error(t, err, input)
+	}
+
+	t.Run("diagnostics", func(t *testing.T) {
+		in := NewInterpreter()
+		_, err := in.Eval(`let y: number = 1;`)
+		require.NoError(t, err)
+		_, err = in.Eval("let x = 1;\ny = \"a\";")
+		var diagnostics compiler.Diagnostics
+		require.ErrorAs(t, err, &diagnostics)
+		assert.Equal(t, compiler.Diagnostics{{Name: "eval", Phase: compiler.PhaseType, Line: 2, Message: "can't assign string to y of type number"}}, diagnostics)
+		assert.EqualError(t, err, "eval:2: type error: can't assign string to y of type number")
+
+		_, err = Parse("let = 1;")
+		require.ErrorAs(t, err, &diagnostics)
+		assert.Equal(t, compiler.PhaseParse, diagnostics[0].Phase)
+	})
+}
+
+func TestValue(t *testing.T) {
+	in := NewInterpreter()
+	got, err := in.Eval(`enum Color { Red } [nil, true, 1, 100000000000000000000, 1.5, "a", [1], {"a": 1}, 1..3, len, Color.Red, channel(1)];`)
+	require.NoError(t, err)
+	values, ok := got.AsList()
+	require.True(t, ok)
+
+	kinds := []Kind{}
+	for _, v := range values {
+		kinds = append(kinds, v.Kind())
+	}
+	assert.Equal(t, []Kind{KindNil, KindBool, KindInt, KindInt, KindFloat, KindString, KindList, KindMap, KindRange, KindFunction, KindEnum, KindOther}, kinds)
+	assert.Equal(t, `[nil, true, 1, 100000000000000000000, 1.5, a, [1], {a: 1}, 1..3, <native len>, Color.Red, <channel 0/1>]`, got.String())
+
+	n, ok := values[2].AsInt()
+	assert.True(t, ok)
+	assert.Equal(t, 1, n)
+	_, ok = values[3].AsInt()
+	assert.False(t, ok)
+	b, ok := values[3].AsBigInt()
+	assert.True(t, ok)
+	assert.Equal(t, "100000000000000000000", b.String())
+	f, ok := values[2].AsFloat()
+	assert.True(t, ok)
+	assert.Equal(t, 1.0, f)
+	s, ok := values[5].AsString()
+	assert.True(t, ok)
+	assert.Equal(t, "a", s)
+	_, ok = values[5].AsInt()
+	assert.False(t, ok)
+	keys, vals, ok := values[7].AsMap()
+	assert.True(t, ok)
+	assert.Equal(t, []Value{NewString("a")}, keys)
+	assert.Equal(t, []Value{NewInt(1)}, vals)
+
+	assert.True(t, Value{}.IsNil())
+	_, ok = Value{}.AsInt()
+	assert.False(t, ok)
+	assert.Equal(t, "nil", Value{}.String())
+
+	t.Run("go conversions", func(t *testing.T) {
+		v, err := FromGo(map[string]any{"name": "lox", "tags": []string{"a"}, "n": int64(2), "f": float32(0.5), "ok": true, "none": nil})
+		require.NoError(t, err)
+		assert.Equal(t, KindMap, v.Kind())
+		assert.Equal(t, map[string]any{"name": "lox", "tags": []any{"a"}, "n": 2, "f": 0.5, "ok": true, "none": nil}, ToGo(v))
+
+		v, err = FromGo(map[int]string{1: "a"})
+		require.NoError(t, err)
+		assert.Equal(t, map[any]any{1: "a"}, ToGo(v))
+
+		assert.Equal(t, values[9], ToGo(values[9]))
+
+		_, err = FromGo(struct{}{})
+		assert.Error(t, err)
+		_, err = FromGo(map[*int]int{nil: 1})
+		assert.Error(t, err)
+	})
+}
+
+func TestLimits(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	testCases := []struct {
+		desc  string
+		input string
+		opts  []Option
+		limit Limit
+	}{
+		{
+			desc:  "infinite loop",
+			input: `while (true) {}`,
+			opts:  []Option{WithMaxSteps(1000)},
+			limit: LimitSteps,
+		},
+		{
+			desc:  "infinite for loop",
+			input: `for (x in 0..1000000000) {}`,
+			opts:  []Option{WithMaxSteps(1000)},
+			limit: LimitSteps,
+		},
+		{
+			desc:  "can't be caught",
+			input: `let caught = false; try { while (true) {} } catch (e) { caught = true; }`,
+			opts:  []Option{WithMaxSteps(1000)},
+			limit: LimitSteps,
+		},
+		{
+			desc:  "interval never stops",
+			input: `function tick() {} setInterval(tick, 0);`,
+			opts:  []Option{WithMaxSteps(1000), WithClock(NewVirtualClock(time.Now()))},
+			limit: LimitSteps,
+		},
+		{
+			desc:  "default call depth",
+			input: `function f(n) { return f(n + 1); } f(0);`,
+			limit: LimitCallDepth,
+		},
+		{
+			desc:  "call depth",
+			input: `function f(n) { if (n == 0) { return 0; } return f(n - 1); } f(20);`,
+			opts:  []Option{WithMaxCallDepth(10)},
+			limit: LimitCallDepth,
+		},
+		{
+			desc:  "cancelled context",
+			input: `while (true) {}`,
+			opts:  []Option{WithContext(cancelled)},
+			limit: LimitContext,
+		},
+		{
+			desc:  "growing string",
+			input: `let s = ""; while (true) { s = s + "abcd"; }`,
+			opts:  []Option{WithMaxAllocations(10000)},
+			limit: LimitAllocations,
+		},
+		{
+			desc:  "repeat",
+			input: `let s = repeat("a", 1000000);`,
+			opts:  []Option{WithMaxAllocations(1000)},
+			limit: LimitAllocations,
+		},
+		{
+			desc:  "growing map",
+			input: `let m = {}; let n = 0; while (true) { m[n] = [n]; n = n + 1; }`,
+			opts:  []Option{WithMaxAllocations(1000)},
+			limit: LimitAllocations,
+		},
+		{
+			desc:  "big power",
+			input: `let n = math.pow(3, 100000);`,
+			opts:  []Option{WithMaxAllocations(10000)},
+			limit: LimitAllocations,
+		},
+		{
+			desc:  "growing big integer",
+			input: `let n = 9223372036854775807; while (true) { n = n * n; }`,
+			opts:  []Option{WithMaxAllocations(10000)},
+			limit: LimitAllocations,
+		},
+		{
+			desc:  "replace",
+			input: `let s = repeat("a", 1000); let t = replace(s, "a", s); replace(t, "a", s);`,
+			opts:  []Option{WithMaxAllocations(100000)},
+			limit: LimitAllocations,
+		},
+		{
+			desc:  "join",
+			input: `let a = repeat("a", 1000); join([a, a, a, a, a, a, a, a, a, a], "");`,
+			opts:  []Option{WithMaxAllocations(5000)},
+			limit: LimitAllocations,
+		},
+		{
+			desc:  "split",
+			input: `split(text, ",");`,
+			opts:  []Option{WithMaxAllocations(500), WithGlobal("text", toValue(strings.Repeat(",", 1000)))},
+			limit: LimitAllocations,
+		},
+		{
+			desc:  "chars",
+			input: `chars(text);`,
+			opts:  []Option{WithMaxAllocations(500), WithGlobal("text", toValue(strings.Repeat("a", 1000)))},
+			limit: LimitAllocations,
+		},
+		{
+			desc:  "slice",
+			input: `text[0..<1000];`,
+			opts:  []Option{WithMaxAllocations(500), WithGlobal("text", toValue(strings.Repeat("a", 1000)))},
+			limit: LimitAllocations,
+		},
+		{
+			desc:  "json.parse",
+			input: `json.parse(text);`,
+			opts:  []Option{WithMaxAllocations(500), WithGlobal("text", toValue("["+strings.Repeat("1,", 1000)+"1]"))},
+			limit: LimitAllocations,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			_, err := NewInterpreter(tC.opts...).Eval(tC.input)
+			var limit *LimitExceeded
+			require.ErrorAs(t, err, &limit)
+			assert.Equal(t, tC.limit, limit.Limit)
+		})
+	}
+
+	t.Run("readFile", func(t *testing.T) {
+		root := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(root, "big.txt"), bytes.Repeat([]byte("a"), 10000), 0644))
+		_, err := NewInterpreter(WithFSRoot(root), WithMaxAllocations(1000)).Eval(`readFile("big.txt");`)
+		var limit *LimitExceeded
+		require.ErrorAs(t, err, &limit)
+		assert.Equal(t, LimitAllocations, limit.Limit)
+	})
+
+	t.Run("within limits", func(t *testing.T) {
+		in := NewInterpreter(WithMaxSteps(1000), WithMaxCallDepth(20), WithMaxAllocations(1000))
+		got, err := in.Eval(`function f(n) { if (n == 0) { return 0; } return 1 + f(n - 1); } f(15);`)
+		require.NoError(t, err)
+		assert.Equal(t, toValue(15), got)
+	})
+
+	// the sleeping goroutine keeps waiting on channels from being a deadlock
+	sleeping := `function wait() { sleep(100000); } spawn wait(); `
+	blocking := []string{`sleep(100000);`, sleeping + `recv(channel(0));`, sleeping + `send(channel(0), 1);`,
+		sleeping + `select { case recv(channel(0)) {} }`, `function f() {} setTimeout(f, 100000);`}
+	for _, input := range blocking {
+		t.Run("timeout "+input, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			_, err := NewInterpreter(WithContext(ctx)).Eval(input)
+			var limit *LimitExceeded
+			require.ErrorAs(t, err, &limit)
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+		})
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	t.Run("all by default", func(t *testing.T) {
+		t.Setenv("LOX_TEST_VAR", "value")
+		got, err := NewInterpreter().Eval(`[getEnv("LOX_TEST_VAR"), getEnv("LOX_TEST_MISSING")];`)
+		require.NoError(t, err)
+		assert.Equal(t, "[value, nil]", got.String())
+	})
+
+	denied := []string{`print(1);`, `readLine();`, `readFile("a");`, `writeFile("a", "b");`, `exists("a");`, `getEnv("HOME");`,
+		`now();`, `clock();`, `sleep(1);`, `function f() {} setTimeout(f, 1);`}
+	for _, input := range denied {
+		t.Run("denied "+input, func(t *testing.T) {
+			_, err := NewInterpreter(WithCapabilities(CapNone)).Eval(input)
+			assert.ErrorIs(t, err, os.ErrPermission)
+		})
+	}
+
+	t.Run("pure natives need nothing", func(t *testing.T) {
+		got, err := NewInterpreter(WithCapabilities(CapNone)).Eval(`[upper("a"), math.abs(-1), formatDate(0, "2006", "UTC")];`)
+		require.NoError(t, err)
+		assert.Equal(t, "[A, 1, 1970]", got.String())
+	})
+
+	t.Run("permission error can be caught", func(t *testing.T) {
+		got, err := NewInterpreter(WithCapabilities(CapNone)).Eval(`let m = nil; try { now(); } catch (e) { m = e.message; } m;`)
+		require.NoError(t, err)
+		assert.Equal(t, "now needs clock capability: permission denied", got.String())
+	})
+
+	t.Run("read only root", func(t *testing.T) {
+		root := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(root, "data.txt"), []byte("inside"), 0644))
+		outside := filepath.Join(filepath.Dir(root), "outside.txt")
+		require.NoError(t, os.WriteFile(outside, []byte("outside"), 0644))
+		t.Cleanup(func() { os.Remove(outside) })
+
+		in := NewInterpreter(WithCapabilities(CapFSRead), WithFSRoot(root))
+		got, err := in.Eval(`[readFile("data.txt"), readFile("/data.txt"), readFile("sub/../data.txt"), exists("../outside.txt"), exists("` + outside + `")];`)
+		require.NoError(t, err)
+		assert.Equal(t, "[inside, inside, inside, false, false]", got.String())
+
+		_, err = in.Eval(`writeFile("data.txt", "changed");`)
+		assert.ErrorIs(t, err, os.ErrPermission)
+		_, err = in.Eval(`print(1);`)
+		assert.ErrorIs(t, err, os.ErrPermission)
+	})
+
+	t.Run("symbolic links stay inside root", func(t *testing.T) {
+		dir := t.TempDir()
+		root, outside := filepath.Join(dir, "root"), filepath.Join(dir, "outside")
+		require.NoError(t, os.Mkdir(root, 0755))
+		require.NoError(t, os.Mkdir(outside, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(root, "data.txt"), []byte("inside"), 0644))
+		require.NoError(t, os.Symlink(filepath.Join(outside, "secret.txt"), filepath.Join(root, "secret.txt")))
+		require.NoError(t, os.Symlink(outside, filepath.Join(root, "out")))
+		require.NoError(t, os.Symlink(filepath.Join(outside, "new.txt"), filepath.Join(root, "dangling.txt")))
+		require.NoError(t, os.Symlink("data.txt", filepath.Join(root, "link.txt")))
+
+		in := NewInterpreter(WithCapabilities(CapFSRead|CapFSWrite), WithFSRoot(root))
+		escaping := []string{`readFile("secret.txt");`, `readFile("out/secret.txt");`, `exists("out/secret.txt");`,
+			`writeFile("out/new.txt", "x");`, `writeFile("dangling.txt", "x");`, `appendFile("dangling.txt", "x");`, `lines("secret.txt");`}
+		for _, input := range escaping {
+			_, err := in.Eval(input)
+			assert.ErrorIs(t, err, os.ErrPermission, input)
+		}
+		assert.NoFileExists(t, filepath.Join(outside, "new.txt"))
+
+		got, err := in.Eval(`writeFile("new.txt", "created"); [readFile("link.txt"), readFile("new.txt"), exists("missing/file.txt")];`)
+		require.NoError(t, err)
+		assert.Equal(t, "[inside, created, false]", got.String())
+	})
+}
+
+func TestGlobals(t *testing.T) {
+	in := NewInterpreter()
+	require.NoError(t, in.SetGlobal("limit", 3))
+	require.NoError(t, in.SetGlobal("names", []string{"a", "b", "c", "d"}))
+	require.NoError(t, in.SetGlobal("len", 100), "globals can shadow natives")
+	assert.Error(t, in.SetGlobal("invalid", struct{}{}))
+	require.NoError(t, in.SetGlobal("zero", Value{}), "zero value is nil")
+
+	var out bytes.Buffer
+	zero := NewInterpreter(WithGlobal("zero", Value{}), WithStdout(&out))
+	got, err := zero.Eval(`print(zero); zero == nil;`)
+	require.NoError(t, err)
+	assert.Equal(t, "nil\n", out.String())
+	assert.Equal(t, true, ToGo(got))
+
+	_, err = in.Eval(`let picked = {}; let n = 0;
+	for (name in names) { if (n < limit) { picked[n] = name; } n = n + 1; }`)
+	require.NoError(t, err)
+
+	picked, ok := in.GetGlobal("picked")
+	require.True(t, ok)
+	assert.Equal(t, map[any]any{0: "a", 1: "b", 2: "c"}, ToGo(picked))
+	shadowed, ok := in.GetGlobal("len")
+	require.True(t, ok)
+	assert.Equal(t, NewInt(100), shadowed)
+	_, ok = in.GetGlobal("missing")
+	assert.False(t, ok)
+
+	zeroGlobal, ok := in.GetGlobal("zero")
+	require.True(t, ok)
+	assert.True(t, zeroGlobal.IsNil())
+
+	got, err = in.Eval(`len + 1;`)
+	require.NoError(t, err, "shadowed natives are not type checked")
+	assert.Equal(t, NewInt(101), got)
+
+	globals := in.Globals()
+	assert.Equal(t, NewInt(4), globals["n"])
+	assert.Contains(t, globals, "print")
+	assert.NotContains(t, globals, "name", "loop variables aren't globals")
+	delete(globals, "n")
+	_, ok = in.GetGlobal("n")
+	assert.True(t, ok, "Globals returns a copy")
+}
+
+type bindAddress struct {
+	City string
+}
+
+type bindUser struct {
+	Name    string
+	Age     int8
+	Email   string `lox:"email"`
+	Tags    []string
+	Address bindAddress
+	secret  string
+}
+
+func (u *bindUser) Greet(greeting string) string {
+	return greeting + ", " + u.Name
+}
+
+func (u *bindUser) SetAge(age int8) error {
+	if age < 0 {
+		return fmt.Errorf("negative age %d", age)
+	}
+	u.Age = age
+	return nil
+}
+
+func (u *bindUser) Tag(tags ...string) int {
+	u.Tags = append(u.Tags, tags...)
+	return len(u.Tags)
+}
+
+func (u *bindUser) TagAt(n int) string {
+	return u.Tags[n]
+}
+
+func (u *bindUser) Friend(name string) *bindUser {
+	return nil
+}
+
+func TestBind(t *testing.T) {
+	user := &bindUser{Name: "bob", Age: 41, Email: "bob@example.com", secret: "x"}
+	in := NewInterpreter()
+	require.NoError(t, in.Bind("user", user))
+	assert.Error(t, in.Bind("invalid", bindUser{}))
+	assert.Error(t, in.Bind("invalid", (*bindUser)(nil)))
+
+	got, err := in.Eval(`user.Name = upper(user.Name);
+	user.Address.City = "Paris";
+	user.SetAge(user.Age + 1);
+	let n = user.Tag("a", "b");
+	[user.Greet("hi"), user.email, user.Address.City, n];`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"hi, BOB", "bob@example.com", "Paris", 2}, ToGo(got))
+	assert.Equal(t, &bindUser{Name: "BOB", Age: 42, Email: "bob@example.com", Tags: []string{"a", "b"}, Address: bindAddress{City: "Paris"}, secret: "x"}, user)
+
+	bound, ok := in.GetGlobal("user")
+	require.True(t, ok)
+	assert.Same(t, user, ToGo(bound))
+
+	testCases := []struct {
+		code     string
+		expected string
+	}{
+		{code: `user.Age = "old";`, expected: `can't set bindUser.Age: expects int8, got "old", line 1`},
+		{code: `user.Age = 1000;`, expected: `can't set bindUser.Age: expects int8, got 1000, line 1`},
+		{code: `user.Tags = [1];`, expected: `can't set bindUser.Tags: element 0: expects string, got 1, line 1`},
+		{code: `user.secret;`, expected: `bindUser has no field or method secret, line 1`},
+		{code: `user.secret = "y";`, expected: `bindUser has no field secret, line 1`},
+		{code: `user.SetAge(-1);`, expected: `bindUser.SetAge: negative age -1`},
+		{code: `user.Greet(1);`, expected: `bindUser.Greet argument 1: expects string, got 1`},
+		{code: `let x = 1; x.y = 2;`, expected: `can't set property y of 1, line 1`},
+		{code: `user.TagAt(5);`, expected: `bindUser.TagAt: panic: runtime error: index out of range [5] with length 2`},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.code, func(t *testing.T) {
+			_, err := in.Eval(tC.code)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tC.expected)
+		})
+	}
+
+	got, err = in.Eval(`let caught = nil;
+	try { user.SetAge("x"); } catch (e) { caught = e.message; }
+	caught;`)
+	require.NoError(t, err)
+	msg, _ := got.AsString()
+	assert.Contains(t, msg, `bindUser.SetAge argument 1: expects int8, got "x"`)
+
+	got, err = in.Eval(`let panicked = nil;
+	try { user.TagAt(-1); } catch (e) { panicked = e.message; }
+	[panicked, user.Friend("alice") == nil];`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"bindUser.TagAt: panic: runtime error: index out of range [-1]", true}, ToGo(got))
+
+	null, err := FromGo((*bindUser)(nil))
+	require.NoError(t, err)
+	assert.True(t, null.IsNil())
+}
+
+func TestDestructuring(t *testing.T) {
+	t.Run("list with rest", func(t *testing.T) {
+		statements := parseIt(t, `let xs = [1, 2, 3, 4];
+		let [a, b, ...rest] = xs;`)
+		in := NewInterpreter()
+
+		execute(t, in, statements)
+
+		assertVariable(t, toValue(1), "a", in)
+		assertVariable(t, toValue(2), "b", in)
+		assertVariable(t, toValue(&LoxList{elements: []Value{toValue(3), toValue(4)}}), "rest", in)
+	})
+
+	t.Run("empty rest", func(t *testing.T) {
+		statements := parseIt(t, `let [a, ...rest] = [1];`)
+		in := NewInterpreter()
+
+		execute(t, in, statements)
+
+		assertVariable(t, toValue(1), "a", in)
+		assertVariable(t, toValue(&LoxList{elements: []Value{}}), "rest", in)
+	})
+
+	t.Run("object", func(t *testing.T) {
+		statements := parseIt(t, `enum Person { P(name, age) }
+		let {name, age} = Person.P("bob", 42);`)
+		in := NewInterpreter()
+
+		execute(t, in, statements)
+
+		assertVariable(t, toValue("bob"), "name", in)
+		assertVariable(t, toValue(42), "age", in)
+	})
+
+	t.Run("swap", func(t *testing.T) {
+		statements := parseIt(t, `let a = 1;
+		let b = 2;
+		[a, b] = [b, a];`)
+		in := NewInterpreter()
+
+		execute(t, in, statements)
+
+		assertVariable(t, toValue(2), "a", in)
+		assertVariable(t, toValue(1), "b", in)
+	})
+
+	invalidCases := []struct {
+		desc  string
+		input string
+	}{
+		{
+			desc:  "too many variables",
+			input: `let [a, b, c] = [1, 2];`,
+		},
+		{
+			desc:  "too many values",
+			input: `let [a] = [1, 2];`,
+		},
+		{
+			desc:  "too many variables with rest",
+			input: `let [a, b, ...c] = [1];`,
+		},
+		{
+			desc:  "not a list",
+			input: `let [a, b] = 4;`,
+		},
+		{
+			desc: "missing property",
+			input: `enum Person { P(name, age) }
+			let {name, email} = Person.P("bob", 42);`,
+		},
+		{
+			desc:  "undeclared variables",
+			input: `[a, b] = [1, 2];`,
+		},
+	}
+	for _, tC := range invalidCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			assert.Error(t, Interpret(parseIt(t, tC.input)))
+		})
+	}
+}
+
+func TestGenerators(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		input    string
+		expected Value
+	}{
+		{
+			desc: "for in over list",
+			input: `let result = 0;
+			for (x in [1, 2, 3]) {
+				result = result + x;
+			}`,
+			expected: toValue(6),
+		},
+		{
+			desc: "for in over generator",
+			input: `function upTo(n) {
+				let i = 1;
+				while (i <= n) {
+					yield i;
+					i = i + 1;
+				}
+			}
+			let result = 0;
+			for (x in upTo(4)) {
+				result = result + x;
+			}`,
+			expected: toValue(10),
+		},
+		{
+			desc: "manual next",
+			input: `function gen() {
+				yield 1;
+				yield 2;
+			}
+			let g = gen();
+			let a = g.next();
+			let b = g.next();
+			let result = (a + b == 3) && (g.next() == nil);`,
+			expected: toValue(true),
+		},
+		{
+			desc: "yield in try and catch",
+			input: `function gen() {
+				try {
+					yield 1;
+					yield 1 + "a";
+				} catch (e) {
+					yield 2;
+				}
+			}
+			let result = 0;
+			for (x in gen()) {
+				result = result + x;
+			}`,
+			expected: toValue(3),
+		},
+		{
+			desc: "yield in select",
+			input: `function drain(ch) {
+				while (true) {
+					select {
+						case v = recv(ch) { yield v; }
+						else { return; }
+					}
+				}
+			}
+			let ch = channel(3);
+			send(ch, 1);
+			send(ch, 2);
+			let result = 0;
+			for (x in drain(ch)) {
+				result = result + x;
+			}`,
+			expected: toValue(3),
+		},
+		{
+			desc: "early break of infinite generator",
+			input: `function naturals() {
+				let i = 0;
+				while (true) {
+					yield i;
+					i = i + 1;
+				}
+			}
+			let result = 0;
+			let g = naturals();
+			for (x in g) {
+				if (x == 5) {
+					break;
+				}
+				result = result + x;
+			}`,
+			expected: toValue(10),
+		},
+		{
+			desc: "break in while",
+			input: `let result = 0;
+			while (true) {
+				result = result + 1;
+				if (result == 3) {
+					break;
+				}
+			}`,
+			expected: toValue(3),
+		},
+		{
+			desc: "nested generators",
+			input: `function gen(n) {
+				yield n;
+				yield n * 10;
+			}
+			function pairs() {
+				for (x in [1, 2]) {
+					for (y in gen(x)) {
+						yield y;
+					}
+				}
+			}
+			let result = 0;
+			for (v in pairs()) {
+				result = result + v;
+			}`,
+			expected: toValue(33),
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			statements := parseIt(t, tC.input)
+			in := NewInterpreter()
+
+			execute(t, in, statements)
+
+			assertVariable(t, tC.expected, "result", in)
+		})
+	}
+
+	t.Run("closed after early break", func(t *testing.T) {
+		statements := parseIt(t, `function naturals() {
+			let i = 0;
+			while (true) {
+				yield i;
+				i = i + 1;
+			}
+		}
+		let g = naturals();
+		for (x in g) {
+			break;
+		}`)
+		in := NewInterpreter()
+
+		execute(t, in, statements)
+
+		v, ok := in.GetGlobal("g")
+		require.True(t, ok)
+		gen, ok := getFromValue[*LoxGenerator](v)
+		require.True(t, ok)
+		assert.True(t, gen.done)
+	})
+
+	t.Run("next from the body of the generator", func(t *testing.T) {
+		testCases := []struct {
+			desc     string
+			input    string
+			expected string
+		}{
+			{
+				desc: "directly",
+				input: `function gen() { yield 1; yield g.next(); }
+				let g = gen();`,
+				expected: "generator gen is already running",
+			},
+			{
+				desc: "through other generator",
+				input: `function outer() { for (x in inner()) { yield x; } }
+				function inner() { yield g.next(); }
+				let g = outer();`,
+				expected: "generator outer is already running",
+			},
+			{
+				desc: "for in",
+				input: `function gen() { for (x in g) { yield x; } }
+				let g = gen();`,
+				expected: "generator gen is already running",
+			},
+		}
+		for _, tC := range testCases {
+			t.Run(tC.desc, func(t *testing.T) {
+				in := NewInterpreter()
+				_, err := in.Eval(tC.input)
+				require.NoError(t, err)
+
+				got, err := in.Eval(`let result = nil; try { g.next(); g.next(); } catch (e) { result = e.message; } result;`)
+				require.NoError(t, err)
+				assert.Contains(t, got.String(), tC.expected)
+			})
+		}
+	})
+
+	t.Run("suspended generator stops with the context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		in := NewInterpreter(WithContext(ctx))
+		_, err := in.Eval(`function naturals() {
+			let i = 0;
+			while (true) {
+				yield i;
+				i = i + 1;
+			}
+		}
+		let g = naturals();
+		g.next();`)
+		require.NoError(t, err)
+
+		v, ok := in.GetGlobal("g")
+		require.True(t, ok)
+		gen, ok := getFromValue[*LoxGenerator](v)
+		require.True(t, ok)
+
+		cancel()
+		select {
+		case step := <-gen.steps:
+			assert.True(t, step.done)
+			assert.ErrorIs(t, step.err, context.Canceled)
+		case <-time.After(time.Second):
+			t.Fatal("generator goroutine is still suspended")
+		}
+	})
+
+	invalidCases := []struct {
+		desc  string
+		input string
+	}{
+		{
+			desc:  "yield outside of generator",
+			input: `yield 1;`,
+		},
+		{
+			desc:  "yield outside of generator is not caught",
+			input: `try { yield 1; } catch (e) { }`,
+		},
+		{
+			desc:  "break outside of loop",
+			input: `break;`,
+		},
+		{
+			desc: "break does not leave function",
+			input: `function foo() {
+				break;
+			}
+			while (true) {
+				foo();
+			}`,
+		},
+		{
+			desc: "error in generator",
+			input: `function gen() {
+				yield 1;
+				yield 1 + "a";
+			}
+			for (x in gen()) { }`,
+		},
+		{
+			desc:  "not iterable",
+			input: `for (x in 4) { }`,
+		},
+	}
+	for _, tC := range invalidCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			assert.Error(t, Interpret(parseIt(t, tC.input)))
+		})
+	}
+}
+
+func TestConcurrency(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		input    string
+		expected Value
+	}{
+		{
+			desc: "producer and consumer",
+			input: `function produce(ch, n) {
+				let i = 1;
+				while (i <= n) {
+					send(ch, i);
+					i = i + 1;
+				}
+				close(ch);
+			}
+			let ch = channel(0);
+			spawn produce(ch, 4);
+			let result = 0;
+			let v = recv(ch);
+			while (v != nil) {
+				result = result + v;
+				v = recv(ch);
+			}`,
+			expected: toValue(10),
+		},
+		{
+			desc: "fan in",
+			input: `function worker(id, out) {
+				send(out, id * id);
+			}
+			let out = channel(2);
+			let i = 1;
+			while (i <= 10) {
+				spawn worker(i, out);
+				i = i + 1;
+			}
+			let result = 0;
+			for (x in [1, 2, 3, 4, 5, 6, 7, 8, 9, 10]) {
+				result = result + recv(out);
+			}`,
+			expected: toValue(385),
+		},
+		{
+			desc: "select without ready channel runs else",
+			input: `let ch = channel(1);
+			let result = 0;
+			select {
+				case v = recv(ch) { result = v; }
+				else { result = -1; }
+			}`,
+			expected: toValue(-1),
+		},
+		{
+			desc: "select ready channel",
+			input: `let a = channel(1);
+			let b = channel(1);
+			send(b, 7);
+			let result = 0;
+			select {
+				case v = recv(a) { result = v; }
+				case v = recv(b) { result = v * 2; }
+			}`,
+			expected: toValue(14),
+		},
+		{
+			desc: "select send",
+			input: `let a = channel(1);
+			select {
+				case send(a, 3) { }
+			}
+			let result = recv(a);`,
+			expected: toValue(3),
+		},
+		{
+			desc: "recv on closed channel",
+			input: `let ch = channel(1);
+			send(ch, 1);
+			close(ch);
+			let first = recv(ch);
+			let result = (first == 1) && (recv(ch) == nil);`,
+			expected: toValue(true),
+		},
+		{
+			desc: "shared globals",
+			input: `function worker(done) {
+				let i = 0;
+				while (i < 100) {
+					counter = i;
+					i = i + 1;
+				}
+				send(done, true);
+			}
+			let counter = 0;
+			let done = channel(0);
+			spawn worker(done);
+			spawn worker(done);
+			recv(done);
+			recv(done);
+			let result = counter;`,
+			expected: toValue(99),
+		},
+		{
+			desc: "shared list and map",
+			input: `function worker(id, xs, m, done) {
+				let i = 0;
+				while (i < 50) {
+					xs[id] = xs[id] + 1;
+					m[id * 100 + i] = i;
+					m["last"] = id;
+					let seen = [keys(m), str(xs)];
+					i = i + 1;
+				}
+				send(done, true);
+			}
+			let xs = [0, 0, 0, 0];
+			let m = {};
+			let done = channel(0);
+			let id = 0;
+			while (id < 4) {
+				spawn worker(id, xs, m, done);
+				id = id + 1;
+			}
+			while (id > 0) {
+				recv(done);
+				id = id - 1;
+			}
+			let result = xs[0] + xs[1] + xs[2] + xs[3] + len(m);`,
+			expected: toValue(401),
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			statements := parseIt(t, tC.input)
+			in := NewInterpreter()
+
+			execute(t, in, statements)
+
+			assertVariable(t, tC.expected, "result", in)
+		})
+	}
+
+	invalidCases := []struct {
+		desc  string
+		input string
+	}{
+		{
+			desc: "send on closed channel",
+			input: `let ch = channel(1);
+			close(ch);
+			send(ch, 1);`,
+		},
+		{
+			desc: "close closed channel",
+			input: `let ch = channel(1);
+			close(ch);
+			close(ch);`,
+		},
+		{
+			desc:  "not a channel",
+			input: `recv(1);`,
+		},
+	}
+	for _, tC := range invalidCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			assert.Error(t, Interpret(parseIt(t, tC.input)))
+		})
+	}
+
+	deadlocks := []struct {
+		desc  string
+		input string
+	}{
+		{
+			desc:  "recv without sender",
+			input: `recv(channel(0));`,
+		},
+		{
+			desc:  "send without receiver",
+			input: `let ch = channel(1); send(ch, 1); send(ch, 2);`,
+		},
+		{
+			desc:  "select without ready case",
+			input: `select { case recv(channel(0)) {} case send(channel(0), 1) {} }`,
+		},
+		{
+			desc: "all goroutines wait",
+			input: `function worker(from, to) {
+				send(to, recv(from));
+			}
+			let a = channel(0);
+			let b = channel(0);
+			spawn worker(a, b);
+			spawn worker(b, a);
+			recv(a);`,
+		},
+		{
+			desc: "spawned goroutine finished without sending",
+			input: `function worker(ch) {}
+			let ch = channel(0);
+			spawn worker(ch);
+			recv(ch);`,
+		},
+		{
+			desc:  "not caught by try",
+			input: `try { recv(channel(0)); } catch (e) {}`,
+		},
+	}
+	for _, tC := range deadlocks {
+		t.Run(tC.desc, func(t *testing.T) {
+			_, err := NewInterpreter(WithStderr(io.Discard)).Eval(tC.input)
+			assert.ErrorIs(t, err, errDeadlock)
+		})
+	}
+
+	t.Run("channel of another interpreter", func(t *testing.T) {
+		ch, err := NewInterpreter().Eval(`channel(1);`)
+		require.NoError(t, err)
+		in := NewInterpreter()
+		require.NoError(t, in.SetGlobal("ch", ch))
+
+		_, err = in.Eval(`send(ch, 1);`)
+		assert.ErrorContains(t, err, "channel of another interpreter")
+	})
+}
+
+func TestReturn(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		input    string
+		expected Value
+	}{
+		{
+			desc: "return value",
+			input: `function add(a, b) {
+				return a + b;
+			}
+			let result = add(2, 3);`,
+			expected: toValue(5),
+		},
+		{
+			desc: "return from loop",
+			input: `function firstAbove(xs, n) {
+				for (x in xs) {
+					while (true) {
+						if (x > n) {
+							return x;
+						}
+						break;
+					}
+				}
+				return -1;
+			}
+			let result = firstAbove([1, 5, 10], 4);`,
+			expected: toValue(5),
+		},
+		{
+			desc: "recursion",
+			input: `function fibo(n) {
+				if (n <= 1) {
+					return n;
+				}
+				return fibo(n - 1) + fibo(n - 2);
+			}
+			let result = fibo(10);`,
+			expected: toValue(55),
+		},
+		{
+			desc: "no return gives nil",
+			input: `function foo() {
+				let x = 1;
+			}
+			let result = foo() == nil;`,
+			expected: toValue(true),
+		},
+		{
+			desc: "return ends generator",
+			input: `function gen() {
+				yield 1;
+				return;
+				yield 2;
+			}
+			let result = 0;
+			for (x in gen()) {
+				result = result + x;
+			}`,
+			expected: toValue(1),
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			statements := parseIt(t, tC.input)
+			in := NewInterpreter()
+
+			execute(t, in, statements)
+
+			assertVariable(t, tC.expected, "result", in)
+		})
+	}
+
+	t.Run("return outside of function", func(t *testing.T) {
+		assert.Error(t, Interpret(parseIt(t, `return 1;`)))
+	})
+}
+
+func TestAsync(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		input    string
+		expected Value
+		elapsed  time.Duration
+	}{
+		{
+			desc: "await async function",
+			input: `async function add(a, b) {
+				return a + b;
+			}
+			let result = await add(1, 2);`,
+			expected: toValue(3),
+		},
+		{
+			desc: "await delay in async function",
+			input: `async function slow(x) {
+				await delay(1000);
+				return x * 2;
+			}
+			let result = await slow(21);`,
+			expected: toValue(42),
+			elapsed:  time.Second,
+		},
+		{
+			desc: "async functions run concurrently",
+			input: `let result = ">";
+			async function step(name, ms) {
+				await delay(ms);
+				result = result + name;
+			}
+			let a = step("a", 300);
+			let b = step("b", 100);
+			let c = step("c", 200);
+			await a;`,
+			expected: toValue(">bca"),
+			elapsed:  300 * time.Millisecond,
+		},
+		{
+			desc: "timers are run in time order",
+			input: `let result = ">";
+			function append(s) {
+				result = result + s;
+			}
+			function first() { append("1"); }
+			function second() { append("2"); }
+			function third() { append("3"); }
+			setTimeout(second, 50);
+			setTimeout(first, 10);
+			setTimeout(third, 50);`,
+			expected: toValue(">123"),
+			elapsed:  50 * time.Millisecond,
+		},
+		{
+			desc: "interval until cleared",
+			input: `let result = 0;
+			let id = 0;
+			function tick() {
+				result = result + 1;
+				if (result == 3) {
+					clearInterval(id);
+				}
+			}
+			id = setInterval(tick, 100);`,
+			expected: toValue(3),
+			elapsed:  300 * time.Millisecond,
+		},
+		{
+			desc: "cleared timeout is not run",
+			input: `let result = 0;
+			function set() {
+				result = 1;
+			}
+			let id = setTimeout(set, 100);
+			clearTimeout(id);`,
+			expected: toValue(0),
+		},
+		{
+			desc: "await non promise value",
+			input: `let result = await 5;`,
+			expected: toValue(5),
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			start := time.Unix(0, 0)
+			clock := NewVirtualClock(start)
+			statements := parseIt(t, tC.input)
+			in := NewInterpreter(WithClock(clock))
+
+			execute(t, in, statements)
+			require.NoError(t, in.RunEventLoop())
+
+			assertVariable(t, tC.expected, "result", in)
+			assert.Equal(t, tC.elapsed, clock.Now().Sub(start))
+		})
+	}
+
+	invalidCases := []struct {
+		desc  string
+		input string
+	}{
+		{
+			desc: "awaited rejection",
+			input: `async function fail() {
+				return 1 + "a";
+			}
+			await fail();`,
+		},
+		{
+			desc: "unhandled rejection",
+			input: `async function fail() {
+				await delay(10);
+				return 1 + "a";
+			}
+			fail();`,
+		},
+		{
+			desc: "failing timer callback",
+			input: `function fail() {
+				let x = 1 + "a";
+			}
+			setTimeout(fail, 10);`,
+		},
+		{
+			desc: "async generator",
+			input: `async function gen() {
+				yield 1;
+			}`,
+		},
+	}
+	for _, tC := range invalidCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			assert.Error(t, Interpret(parseIt(t, tC.input)))
+		})
+	}
+
+	settled := func(t *testing.T, in *Interpreter, name string) error {
+		v, ok := in.GetGlobal(name)
+		require.True(t, ok)
+		promise, ok := getFromValue[*LoxPromise](v)
+		require.True(t, ok)
+
+		done := make(chan struct{})
+		promise.onSettle(func() { close(done) })
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("async function is still suspended")
+		}
+		_, err := promise.result()
+		return err
+	}
+
+	t.Run("suspended async function stops with the context", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		in := NewInterpreter(WithContext(ctx))
+		_, err := in.Eval(`async function wait() { await delay(100000); } let p = wait();`)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		assert.ErrorIs(t, settled(t, in, "p"), context.DeadlineExceeded)
+	})
+
+	t.Run("suspended async function stops when the loop is closed", func(t *testing.T) {
+		in := NewInterpreter()
+		_, err := in.Eval(`let p = nil;
+		async function wait() { await delay(0); await p; }
+		p = wait();`)
+		require.NoError(t, err)
+
+		in.loop.close()
+		assert.ErrorIs(t, settled(t, in, "p"), errLoopClosed)
+	})
+
+	t.Run("timeout cleared while the loop waits for it", func(t *testing.T) {
+		in := NewInterpreter()
+		execute(t, in, parseIt(t, `let result = 0;
+		function set() { result = 1; }
+		let id = setTimeout(set, 300);
+		function cancel() { sleep(20); clearTimeout(id); }
+		spawn cancel();`))
+
+		require.NoError(t, in.RunEventLoop())
+		assertVariable(t, toValue(0), "result", in)
+	})
+
+	t.Run("await in spawned goroutine", func(t *testing.T) {
+		got, err := NewInterpreter().Eval(`function worker(ch) {
+			try { await delay(10); send(ch, "awaited"); } catch (e) { send(ch, e.message); }
+		}
+		let ch = channel(0);
+		spawn worker(ch);
+		recv(ch);`)
+		require.NoError(t, err)
+		assert.Contains(t, got.String(), "spawned goroutine")
+	})
+}
+
+func TestBigIntegers(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		input    string
+		expected string
+	}{
+		{
+			desc:     "addition overflow",
+			input:    `let result = 9223372036854775807 + 1;`,
+			expected: "9223372036854775808",
+		},
+		{
+			desc:     "subtraction overflow",
+			input:    `let result = -9223372036854775807 - 2;`,
+			expected: "-9223372036854775809",
+		},
+		{
+			desc:     "multiplication overflow",
+			input:    `let result = 4294967296 * 4294967296;`,
+			expected: "18446744073709551616",
+		},
+		{
+			desc:     "big literal",
+			input:    `let result = 123456789012345678901234567890 % 1000;`,
+			expected: "890",
+		},
+		{
+			desc:     "negation",
+			input:    `let result = -(9223372036854775807 + 1);`,
+			expected: "-9223372036854775808",
+		},
+		{
+			desc:     "mixed comparison",
+			input:    `let result = 9223372036854775807 + 1 > 5;`,
+			expected: "true",
+		},
+		{
+			desc: "fibonacci",
+			input: `let prev = 0;
+			let result = 1;
+			let i = 1;
+			while (i < 100) {
+				let next = prev + result;
+				prev = result;
+				result = next;
+				i = i + 1;
+			}`,
+			expected: "354224848179261915075",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			statements := parseIt(t, tC.input)
+			in := NewInterpreter()
+
+			execute(t, in, statements)
+
+			v, ok := in.GetGlobal("result")
+			require.True(t, ok)
+			assert.Equal(t, tC.expected, stringify(*v.v))
+		})
+	}
+
+	t.Run("results fitting into int are ints again", func(t *testing.T) {
+		statements := parseIt(t, `let result = (9223372036854775807 + 10) - 20;`)
+		in := NewInterpreter()
+
+		execute(t, in, statements)
+
+		assertVariable(t, toValue(9223372036854775797), "result", in)
+	})
+
+	t.Run("big integers stay big", func(t *testing.T) {
+		statements := parseIt(t, `let result = 9223372036854775807 * 2;`)
+		in := NewInterpreter()
+
+		execute(t, in, statements)
+
+		v, ok := in.GetGlobal("result")
+		require.True(t, ok)
+		_, isBig := getFromValue[*big.Int](v)
+		assert.True(t, isBig)
+	})
+
+	t.Run("division by zero", func(t *testing.T) {
+		assert.Error(t, Interpret(parseIt(t, `let x = 1 / 0;`)))
+		assert.Error(t, Interpret(parseIt(t, `let x = (9223372036854775807 + 1) % 0;`)))
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		_, err := NewInterpreter().Eval(`math.pow(3, 1000000000000);`)
+		assert.ErrorContains(t, err, "math.pow: integer of 1000000000000 bits is too large")
+		_, err = NewInterpreter().Eval(`let n = math.pow(2, 50000000); n * n;`)
+		assert.ErrorContains(t, err, "integer of 100000002 bits is too large, line 1")
+
+		got, err := NewInterpreter().Eval(`[math.pow(1, 1000000000000), math.pow(-1, 1000000000001), math.pow(0, 1000000000000)];`)
+		require.NoError(t, err)
+		assert.Equal(t, "[1, -1, 0]", got.String())
+	})
+}
+
 func assertVariable[T any](t *testing.T, exp T, name string, i *Interpreter) {
-	v, ok := i.env.get(name)
+	v, ok := i.GetGlobal(name)
 	require.True(t, ok, fmt.Sprintf("%v variable not found", name))
 	assert.Equal(t, exp, v)
 }
 
 func assertVariableNotFound(t *testing.T, name string, i *Interpreter) {
-	v, ok := i.env.get(name)
+	v, ok := i.GetGlobal(name)
 	assert.False(t, ok, fmt.Sprintf("%v variable found, but not expected, v: %v", name, v))
 }
 
@@ -445,6 +3212,14 @@ func parseIt(t *testing.T, input string) []parser.Statement {
 	return got
 }
 
+// chanWriter passes every write to the channel
+type chanWriter chan string
+
+func (c chanWriter) Write(p []byte) (int, error) {
+	c <- string(p)
+	return len(p), nil
+}
+
 func execute(t *testing.T, in *Interpreter, stmts []parser.Statement) {
 	for _, st := range stmts {
 		err := st.AcceptStatement(in)
@@ -1,109 +1,233 @@
 package interpreter
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"lox/lexer"
 	"lox/parser"
+	"lox/typecheck"
+	"math"
+	"math/big"
+	"os"
 	"strconv"
+	"strings"
+	"sync"
 )
 
+var errBreak = errors.New("break outside of loop")
+
+// returnSignal unwinds the function body up to the call, like errBreak unwinds the loop
+type returnSignal struct {
+	value Value
+	line  int
+}
+
+func (r returnSignal) Error() string {
+	return fmt.Sprintf("return outside of function, line %v", r.line)
+}
+
 type Interpreter struct {
-	env *environment
+	env       *environment
+	globals   *environment
+	loop      *eventLoop
+	generator *LoxGenerator
+	task      *asyncTask
+	stdin     *bufio.Reader
+	stdinOnce sync.Once
+	stdout    io.Writer
+	stderr    io.Writer
+	limits    *limits
+	depth     int
+	// goroutines are shared with spawned goroutines to detect a deadlock
+	goroutines *goroutines
+	// spawned is set in goroutines started by spawn, only the main goroutine runs the event loop
+	spawned bool
+	// checker keeps declarations of sources evaluated by Eval, it's created by the first one.
+	// Globals declared from Go before are kept in declared
+	checker  *typecheck.Checker
+	declared map[string]typecheck.Type
+	// capabilities and fsRoot are used only while natives are defined
+	capabilities Capability
+	fsRoot       string
+	// globals set by options are defined after the standard library, so they can shadow it
+	optionGlobals map[string]Value
 }
 
-func NewInterpreter() *Interpreter {
+func NewInterpreter(opts ...Option) *Interpreter {
 	env := newEnv()
-	initStdLib(env)
-	return &Interpreter{
-		env: env,
+	lim := newLimits()
+	i := &Interpreter{
+		env:          env,
+		globals:      env,
+		loop:         newEventLoop(lim),
+		limits:       lim,
+		goroutines:   newGoroutines(lim),
+		stdout:       os.Stdout,
+		stderr:       os.Stderr,
+		capabilities: CapAll,
+	}
+	for _, opt := range opts {
+		opt(i)
+	}
+	initStdLib(i)
+	for name, v := range i.optionGlobals {
+		i.globals.create(name, v)
+		i.declare(name, typecheck.Any)
+	}
+	return i
+}
+
+func initStdLib(i *Interpreter) {
+	env := i.globals
+	i.defineNative(nativeFunction{name: "print", arity: 1, needs: CapStdio, fn: func(args []Value) (Value, error) {
+		fmt.Fprintln(i.stdout, stringify(*args[0].v))
+		return toValue(nil), nil
+	}})
+
+	env.create("math", toValue(mathModule(i.limits)))
+	env.create("random", toValue(randomModule(newLockedRand())))
+	env.create("json", toValue(jsonModule(i.limits)))
+	for _, native := range stringNatives(i) {
+		i.defineNative(native)
+	}
+	for _, native := range mapNatives(i) {
+		i.defineNative(native)
+	}
+	for _, native := range timeNatives(i) {
+		i.defineNative(native)
+	}
+	for _, native := range ioNatives(i) {
+		i.defineNative(native)
+	}
+	for _, native := range channelNatives(i) {
+		i.defineNative(native)
+	}
+	for _, native := range timerNatives(i) {
+		i.defineNative(native)
 	}
 }
 
-func initStdLib(env *environment) {
-	build := func(args []string, fn func([]any) error) LoxObject {
-		return toLoxObj(LoxFunction{
-			body: parser.BlockStatement{
-				Stmts: []parser.Statement{
-					parser.NativeCallStatement{
-						Fn: fn,
-						Args: args,
-					},
-				},
-			},
-			args: args,
-		})
+
+// typeChecker returns the checker of Eval, programs run by lox.Run never need one
+func (i *Interpreter) typeChecker() *typecheck.Checker {
+	if i.checker == nil {
+		i.checker = typecheck.NewChecker()
+		for name, t := range i.declared {
+			i.checker.Declare(name, t)
+		}
+		i.declared = nil
 	}
+	return i.checker
+}
 
-	env.create("print", build([]string{"str"}, func(args []any) error {
-		fmt.Println(args[0])
-		return nil
-	}))
+// declare tells the type checker about a global defined from Go
+func (i *Interpreter) declare(name string, t typecheck.Type) {
+	if i.checker != nil {
+		i.checker.Declare(name, t)
+		return
+	}
+	if i.declared == nil {
+		i.declared = map[string]typecheck.Type{}
+	}
+	i.declared[name] = t
 }
 
+// input returns the reader of readLine, the one of os.Stdin is created by the first read
+func (i *Interpreter) input() *bufio.Reader {
+	i.stdinOnce.Do(func() {
+		if i.stdin == nil {
+			i.stdin = bufio.NewReader(os.Stdin)
+		}
+	})
+	return i.stdin
+}
+
+// fork creates interpreter for a separate goroutine, which starts in the given environment
+func (i *Interpreter) fork(env *environment) *Interpreter {
+	return &Interpreter{env: env, globals: i.globals, loop: i.loop, stdin: i.stdin, stdout: i.stdout, stderr: i.stderr,
+		limits: i.limits, depth: i.depth, goroutines: i.goroutines, spawned: i.spawned}
+}
 
 func Interpret(stms []parser.Statement) error {
 	i := NewInterpreter()
+	defer i.loop.close()
 	for _, stmt := range stms {
 		err := stmt.AcceptStatement(i)
 		if err != nil {
 			return err
 		}
 	}
-	return nil
+	return i.RunEventLoop()
+}
+
+// RunEventLoop runs pending timers and async functions until there is nothing left to do
+func (i *Interpreter) RunEventLoop() error {
+	return i.loop.run(nil)
 }
 
 func (i *Interpreter) VisitStatementExpression(s parser.StatementExpression) error {
-	v, err := s.Expression.AcceptExpr(i)
-	_ = v // todo
+	// the value is returned only by Eval
+	_, err := s.Expression.AcceptExpr(i)
 	return err
 }
 
 func (i *Interpreter) VisitLetStatement(let parser.LetStatement) error {
-	return i.doAssignment(let.AssignmentStatement, func(name string, lo LoxObject) error {
+	return i.doAssignment(let.AssignmentStatement, func(name string, lo Value) error {
 		i.env.create(name, lo)
 		return nil
 	})
 }
 
 func (i *Interpreter) VisitAssignmentStatement(assign parser.AssignmentStatement) error {
-	return i.doAssignment(assign, func(name string, lo LoxObject) error {
+	return i.doAssignment(assign, func(name string, lo Value) error {
 		return i.env.put(name, lo)
 	})
 }
 
-func (i *Interpreter) doAssignment(assign parser.AssignmentStatement, do func(string, LoxObject) error) error {
+func (i *Interpreter) doAssignment(assign parser.AssignmentStatement, do func(string, Value) error) error {
 	v, err := assign.Expression.AcceptExpr(i)
 	if err != nil {
 		return err
 	}
 
-	if boolExp, ok := canCast[bool](&v); ok {
-		return do(assign.Name, toLoxObj(boolExp))
-	} else if intExp, ok := canCast[int](&v); ok {
-		return do(assign.Name, toLoxObj(intExp))
-	} else if strExp, ok := canCast[string](&v); ok {
-		return do(assign.Name, toLoxObj(strExp))
+	obj, ok := v.(Value)
+	if !ok {
+		return fmt.Errorf("unknown type of variable %v", assign.Name)
 	}
-
-	return fmt.Errorf("unknown type of variable %v", assign.Name)
+	return do(assign.Name, obj)
 }
 
 func (i *Interpreter) VisitLiteral(li parser.Literal) (any, error) {
 	tok := lexer.Token(li)
-	if lexer.CheckTokenType(tok, lexer.Number) {
+	if lexer.CheckTokenType(tok, lexer.Number) && strings.Contains(li.Lexeme, ".") {
+		v, err := strconv.ParseFloat(li.Lexeme, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %v, line %v, error: %w", li, li.Line, err)
+		}
+		return toValue(v), nil
+	} else if lexer.CheckTokenType(tok, lexer.Number) {
 		v, err := strconv.Atoi(li.Lexeme)
+		if errors.Is(err, strconv.ErrRange) {
+			if b, ok := new(big.Int).SetString(li.Lexeme, 10); ok {
+				return toValue(b), nil
+			}
+		}
 		if err != nil {
 			return nil, fmt.Errorf("invalid number %v, line %v, error: %w", li, li.Line, err)
 		}
-		return toLoxObj(v), nil
+		return toValue(v), nil
 	} else if lexer.CheckTokenType(tok, lexer.StringLiteral) {
-		return toLoxObj(li.Lexeme), nil
+		return toValue(li.Lexeme), nil
 	} else if lexer.CheckTokenType(tok, lexer.Boolean) {
 		v, err := strconv.ParseBool(li.Lexeme)
 		if err != nil {
 			return nil, fmt.Errorf("invalid boolean %v, line %v, error: %w", li, li.Line, err)
 		}
-		return toLoxObj(v), nil
+		return toValue(v), nil
+	} else if lexer.CheckTokenType(tok, lexer.Nil) {
+		return toValue(nil), nil
 	} else if lexer.CheckTokenType(tok, lexer.Identifier) {
 		v, ok := i.env.get(tok.Lexeme)
 		if !ok {
@@ -122,18 +246,26 @@ func (i *Interpreter) VisitUnary(u parser.Unary) (any, error) {
 		return nil, err
 	}
 
+	if v, ok, err := i.callOperator(unaryMethods[op], exp); ok || err != nil {
+		return v, err
+	}
+
 	if op == "!" {
 		v, err := castTo[bool](u.Op, &exp)
 		if err != nil {
 			return nil, err
 		}
-		return toLoxObj(!v), nil
+		return toValue(!v), nil
 	} else if op == "-" {
-		v, err := castTo[int](u.Op, &exp)
-		if err != nil {
-			return nil, err
+		if v, ok := canCast[int](&exp); ok && v != math.MinInt {
+			return toValue(-v), nil
+		} else if v, ok := toBigInt(exp); ok {
+			return normalizeBigInt(new(big.Int).Neg(v)), nil
+		} else if v, ok := canCast[float64](&exp); ok {
+			return toValue(-v), nil
 		}
-		return toLoxObj(-v), nil
+		_, err := castTo[int](u.Op, &exp)
+		return nil, err
 	}
 	return nil, fmt.Errorf("invalid unary operator %v, line %v", u.Op, u.Op.Line)
 }
@@ -149,9 +281,9 @@ func (i *Interpreter) VisitBinary(b parser.Binary) (any, error) {
 		leftBool, leftErr := castTo[bool](b.Op, &leftV)
 		if leftErr == nil {
 			if b.Op.Lexeme == "||" && leftBool {
-				return toLoxObj(true), nil
+				return toValue(true), nil
 			} else if b.Op.Lexeme == "&&" && !leftBool {
-				return toLoxObj(false), nil
+				return toValue(false), nil
 			}
 		}
 	}
@@ -161,18 +293,50 @@ func (i *Interpreter) VisitBinary(b parser.Binary) (any, error) {
 		return nil, rightErr
 	}
 
+	switch b.Op.Lexeme {
+	case "..", "..<":
+		r, err := newRange(b.Op, leftV, rightV)
+		if err != nil {
+			return nil, err
+		}
+		return toValue(r), nil
+	case "in":
+		found, err := i.contains(rightV.(Value), leftV.(Value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid membership test: %w, line %v", err, b.Op.Line)
+		}
+		return toValue(found), nil
+	}
+
+	if leftNil, rightNil := isNil(leftV), isNil(rightV); leftNil || rightNil {
+		switch b.Op.Lexeme {
+		case "==":
+			return toValue(leftNil && rightNil), nil
+		case "!=":
+			return toValue(leftNil != rightNil), nil
+		}
+		return nil, fmt.Errorf("unsupported binary operator on nil %v, line %v", b.Op, b.Op.Line)
+	}
+
+	if v, ok, err := i.binaryOperator(b.Op.Lexeme, leftV, rightV); ok || err != nil {
+		if err != nil {
+			return nil, fmt.Errorf("%w, line %v", err, b.Op.Line)
+		}
+		return v, nil
+	}
+
 	leftBool, leftErr := castTo[bool](b.Op, &leftV)
 	rightBool, rightErr := castTo[bool](b.Op, &rightV)
 	if leftErr == nil && rightErr == nil {
 		switch b.Op.Lexeme {
 		case "!=":
-			return toLoxObj(leftBool != rightBool), nil
+			return toValue(leftBool != rightBool), nil
 		case "==":
-			return toLoxObj(leftBool == rightBool), nil
+			return toValue(leftBool == rightBool), nil
 		case "||":
-			return toLoxObj(leftBool || rightBool), nil
+			return toValue(leftBool || rightBool), nil
 		case "&&": 
-			return toLoxObj(leftBool && rightBool), nil
+			return toValue(leftBool && rightBool), nil
 		}
 		return nil, fmt.Errorf("unsupported binary operator boolean strings %v, line %v", b.Op, b.Op.Line)
 	}
@@ -182,11 +346,14 @@ func (i *Interpreter) VisitBinary(b parser.Binary) (any, error) {
 	if leftErr == nil && rightErr == nil {
 		switch b.Op.Lexeme {
 		case "+":
-			return toLoxObj(leftStr + rightStr), nil
+			if err := i.limits.allocate(len(leftStr) + len(rightStr)); err != nil {
+				return nil, err
+			}
+			return toValue(leftStr + rightStr), nil
 		case "==":
-			return toLoxObj(leftStr == rightStr), nil
+			return toValue(leftStr == rightStr), nil
 		case "!=":
-			return toLoxObj(leftStr != rightStr), nil
+			return toValue(leftStr != rightStr), nil
 		}
 		return nil, fmt.Errorf("unsupported binary operator on strings %v, line %v", b.Op, b.Op.Line)
 	}
@@ -194,31 +361,82 @@ func (i *Interpreter) VisitBinary(b parser.Binary) (any, error) {
 	leftI, leftErr := castTo[int](b.Op, &leftV)
 	rightI, rightErr := castTo[int](b.Op, &rightV)
 	if leftErr == nil && rightErr == nil {
+		// overflowing operations fall through to big integers
 		switch b.Op.Lexeme {
 		case "+":
-			return toLoxObj(leftI + rightI), nil
+			if v, ok := addInt(leftI, rightI); ok {
+				return toValue(v), nil
+			}
 		case "-":
-			return toLoxObj(leftI - rightI), nil
+			if v, ok := subInt(leftI, rightI); ok {
+				return toValue(v), nil
+			}
 		case "*":
-			return toLoxObj(leftI * rightI), nil
+			if v, ok := mulInt(leftI, rightI); ok {
+				return toValue(v), nil
+			}
 		case "/":
-			return toLoxObj(leftI / rightI), nil
+			if rightI == 0 {
+				return nil, fmt.Errorf("division by zero, line %v", b.Op.Line)
+			} else if leftI != math.MinInt || rightI != -1 {
+				return toValue(leftI / rightI), nil
+			}
 		case "%":
-			return toLoxObj(leftI % rightI), nil
+			if rightI == 0 {
+				return nil, fmt.Errorf("division by zero, line %v", b.Op.Line)
+			}
+			return toValue(leftI % rightI), nil
 		case ">":
-			return toLoxObj(leftI > rightI), nil
+			return toValue(leftI > rightI), nil
 		case ">=":
-			return toLoxObj(leftI >= rightI), nil
+			return toValue(leftI >= rightI), nil
 		case "<":
-			return toLoxObj(leftI < rightI), nil
+			return toValue(leftI < rightI), nil
 		case "<=":
-			return toLoxObj(leftI <= rightI), nil
+			return toValue(leftI <= rightI), nil
 		case "!=":
-			return toLoxObj(leftI != rightI), nil
+			return toValue(leftI != rightI), nil
 		case "==":
-			return toLoxObj(leftI == rightI), nil
+			return toValue(leftI == rightI), nil
+		default:
+			return nil, fmt.Errorf("unsupported binary operator on int %v, line %v", b.Op, b.Op.Line)
 		}
-		return nil, fmt.Errorf("unsupported binary operator on int %v, line %v", b.Op, b.Op.Line)
+	}
+
+	leftBig, leftOk := toBigInt(leftV)
+	rightBig, rightOk := toBigInt(rightV)
+	if leftOk && rightOk {
+		return bigIntBinary(i.limits, b.Op, leftBig, rightBig)
+	}
+
+	leftF, leftOk := toFloat(leftV)
+	rightF, rightOk := toFloat(rightV)
+	if leftOk && rightOk {
+		return floatBinary(b.Op, leftF, rightF)
+	}
+
+	leftEnum, leftErr := castTo[*LoxEnumValue](b.Op, &leftV)
+	rightEnum, rightErr := castTo[*LoxEnumValue](b.Op, &rightV)
+	if leftErr == nil && rightErr == nil {
+		switch b.Op.Lexeme {
+		case "==":
+			return toValue(leftEnum == rightEnum), nil
+		case "!=":
+			return toValue(leftEnum != rightEnum), nil
+		}
+		return nil, fmt.Errorf("unsupported binary operator on enums %v, line %v", b.Op, b.Op.Line)
+	}
+
+	leftInstance, leftErr := castTo[*LoxInstance](b.Op, &leftV)
+	rightInstance, rightErr := castTo[*LoxInstance](b.Op, &rightV)
+	if leftErr == nil && rightErr == nil {
+		switch b.Op.Lexeme {
+		case "==":
+			return toValue(leftInstance == rightInstance), nil
+		case "!=":
+			return toValue(leftInstance != rightInstance), nil
+		}
+		return nil, fmt.Errorf("unsupported binary operator on instances %v, line %v", b.Op, b.Op.Line)
 	}
 	return nil, fmt.Errorf("unsupported binary operator, unknown type %v, line %v", b.Op, b.Op.Line)
 }
@@ -236,6 +454,9 @@ func (i *Interpreter) blockStatementEval(b parser.BlockStatement, previous *envi
 	i.env = scopeEnv
 
 	for _, s := range b.Stmts {
+		if err := i.limits.step(); err != nil {
+			return err
+		}
 		if err := s.AcceptStatement(i); err != nil {
 			return err
 		}
@@ -262,6 +483,9 @@ func (i *Interpreter) VisitIfStatement(ifStmt parser.IfStatement) error {
 
 func (i *Interpreter) VisitWhileStatement(whileStmt parser.WhileStatement) error {
 	for {
+		if err := i.limits.step(); err != nil {
+			return err
+		}
 		v, err := whileStmt.Predicate.AcceptExpr(i)
 		if err != nil {
 			return fmt.Errorf("error during evaluating while predicate: %w", err)
@@ -275,7 +499,9 @@ func (i *Interpreter) VisitWhileStatement(whileStmt parser.WhileStatement) error
 		if !boolExp {
 			break
 		}
-		if err = whileStmt.Body.AcceptStatement(i); err != nil {
+		if err = whileStmt.Body.AcceptStatement(i); errors.Is(err, errBreak) {
+			break
+		} else if err != nil {
 			return fmt.Errorf("error during processing while block: %w", err)
 		}
 	}
@@ -283,46 +509,714 @@ func (i *Interpreter) VisitWhileStatement(whileStmt parser.WhileStatement) error
 }
 
 func (i *Interpreter) VisitFunctionDeclarationStatement(fn parser.FunctionDeclaration) error {
-	i.env.create(fn.Name, toLoxObj(LoxFunction{
-		body: fn.Body,
-		args: fn.Args,
+	i.env.create(fn.Name, toValue(LoxFunction{
+		body:      fn.Body,
+		args:      fn.Args,
+		generator: parser.ContainsYield(fn.Body.Stmts),
 	}))
 	return nil
 }
 
-func (i *Interpreter) VisitNativeCallStatement(fn parser.NativeCallStatement) error {
-	args := []any{}
-	for _, arg := range fn.Args {
-		a, ok := i.env.get(arg)
+func (i *Interpreter) VisitFunctionCall(call parser.FunctionCall) (any, error) {
+	obj, ok := i.env.get(call.Name)
+	if !ok {
+		return nil, fmt.Errorf("can't find function %v", call.Name)
+	}
+
+	args, err := i.evaluateArgs(call.Name, call.Args)
+	if err != nil {
+		return nil, err
+	}
+	return i.callObject(call.Name, obj, args)
+}
+
+func (i *Interpreter) VisitCall(call parser.Call) (any, error) {
+	v, err := call.Callee.AcceptExpr(i)
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := v.(Value)
+	if !ok {
+		return nil, fmt.Errorf("invalid call target")
+	}
+
+	name := stringify(*obj.v)
+	args, err := i.evaluateArgs(name, call.Args)
+	if err != nil {
+		return nil, err
+	}
+	return i.callObject(name, obj, args)
+}
+
+func (i *Interpreter) evaluateArgs(name string, argExprs []parser.Expression) ([]Value, error) {
+	args := []Value{}
+	for _, arg := range argExprs {
+		v, err := arg.AcceptExpr(i)
+		if err != nil {
+			return nil, fmt.Errorf("error evaluating args to function %v: %w", name, err)
+		}
+		argObj, ok := v.(Value)
 		if !ok {
-			return fmt.Errorf("native call error - can't find variable %v", arg)
+			return nil, fmt.Errorf("invalid argument passed to function %v", name)
 		}
-		args = append(args, *a.v)
+		args = append(args, argObj)
 	}
-	return fn.Fn(args)
+	return args, nil
 }
 
-func (i *Interpreter) VisitFunctionCall(call parser.FunctionCall) (any, error) {
-	obj, ok := i.env.get(call.Name)
+func (i *Interpreter) callObject(name string, obj Value, args []Value) (any, error) {
+	if err := i.enterCall(); err != nil {
+		return nil, err
+	}
+	defer i.leaveCall()
+
+	if fun, ok := getFromValue[LoxFunction](obj); ok {
+		if len(args) != len(fun.args) {
+			return nil, fmt.Errorf("function %v expects %d arguments, got %d", name, len(fun.args), len(args))
+		}
+
+		scopedEnv := newEnv()
+		for j, arg := range args {
+			scopedEnv.create(fun.args[j], arg)
+		}
+
+		if fun.async {
+			return toValue(i.callAsync(name, fun, scopedEnv)), nil
+		} else if fun.generator {
+			env := i.env
+			return toValue(newGenerator(name, i.limits, func(g *LoxGenerator) error {
+				in := i.fork(env)
+				in.generator = g
+				return functionError(name, in.blockStatementEval(fun.body, env, scopedEnv))
+			})), nil
+		}
+
+		return i.callFunction(name, fun, scopedEnv)
+	} else if native, ok := getFromValue[nativeFunction](obj); ok {
+		if native.arity != Variadic && len(args) != native.arity {
+			return nil, fmt.Errorf("function %v expects %d arguments, got %d", name, native.arity, len(args))
+		}
+		ret, err := native.fn(args)
+		if err == nil && ret.v == nil {
+			// natives defined by embedders may return zero Value
+			ret = toValue(nil)
+		}
+		return ret, err
+	} else if method, ok := getFromValue[boundMethod](obj); ok {
+		if len(args) != len(method.fn.args)-1 {
+			return nil, fmt.Errorf("method %v expects %d arguments, got %d", method.name, len(method.fn.args)-1, len(args))
+		}
+		return i.callObject(method.name, toValue(method.fn), append([]Value{method.self}, args...))
+	} else if variant, ok := getFromValue[*LoxEnumVariant](obj); ok {
+		if len(args) != len(variant.fields) {
+			return nil, fmt.Errorf("variant %v.%v expects %d values, got %d", variant.enum.name, variant.name, len(variant.fields), len(args))
+		}
+		return toValue(&LoxEnumValue{variant: variant, values: args}), nil
+	} else if class, ok := getFromValue[*LoxClass](obj); ok {
+		instance := newInstance(class)
+		if init, ok := instance.method("init"); ok {
+			if _, err := i.callObject(init.name, toValue(init), args); err != nil {
+				return nil, err
+			}
+		} else if len(args) != 0 {
+			return nil, fmt.Errorf("class %v expects 0 arguments, got %d", class.name, len(args))
+		}
+		return toValue(instance), nil
+	}
+	return nil, fmt.Errorf("%v is not a function", name)
+}
+
+// callFunction runs the body of the function in scopedEnv, which holds its arguments
+func (i *Interpreter) callFunction(name string, fun LoxFunction, scopedEnv *environment) (Value, error) {
+	err := i.blockStatementEval(fun.body, i.env, scopedEnv)
+	var ret returnSignal
+	if errors.As(err, &ret) {
+		return ret.value, nil
+	} else if err != nil {
+		return Value{}, functionError(name, err)
+	}
+	return toValue(nil), nil
+}
+
+func functionError(name string, err error) error {
+	var ret returnSignal
+	if err == nil || errors.Is(err, errGeneratorClosed) || errors.As(err, &ret) {
+		return err
+	} else if errors.Is(err, errBreak) {
+		// break can't leave the function and stop the loop of the caller
+		return fmt.Errorf("error during evaluating function %v: %v", name, err)
+	}
+	return &frameError{function: name, err: err}
+}
+
+func (i *Interpreter) VisitGet(g parser.Get) (any, error) {
+	v, err := g.Object.AcceptExpr(i)
+	if err != nil {
+		return nil, err
+	}
+
+	prop, err := i.getProperty(v, g.Name.Lexeme)
+	if err != nil {
+		return nil, fmt.Errorf("%w, line %v", err, g.Name.Line)
+	}
+	return prop, nil
+}
+
+func (i *Interpreter) getProperty(v any, name string) (Value, error) {
+	if enum, ok := canCast[*LoxEnum](&v); ok {
+		variant, ok := enum.variant(name)
+		if !ok {
+			return Value{}, fmt.Errorf("enum %v has no variant %v", enum.name, name)
+		} else if variant.instance != nil {
+			return toValue(variant.instance), nil
+		}
+		return toValue(variant), nil
+	} else if value, ok := canCast[*LoxEnumValue](&v); ok {
+		if field, ok := value.field(name); ok {
+			return field, nil
+		} else if method, ok := value.method(name); ok {
+			return toValue(method), nil
+		}
+		return Value{}, fmt.Errorf("%v has no field %v", value, name)
+	} else if instance, ok := canCast[*LoxInstance](&v); ok {
+		if field, ok := instance.field(name); ok {
+			return field, nil
+		} else if method, ok := instance.method(name); ok {
+			return toValue(method), nil
+		}
+		return Value{}, fmt.Errorf("%v has no field or method %v", instance, name)
+	} else if gen, ok := canCast[*LoxGenerator](&v); ok {
+		return gen.property(i.generator, name)
+	} else if r, ok := canCast[*LoxRange](&v); ok {
+		return r.property(name)
+	} else if m, ok := canCast[*LoxMap](&v); ok {
+		if value, ok := m.property(name); ok {
+			return value, nil
+		}
+		return Value{}, fmt.Errorf("map has no key %v", name)
+	} else if e, ok := canCast[*LoxError](&v); ok {
+		return e.property(name)
+	} else if obj, ok := canCast[*goObject](&v); ok {
+		return obj.property(name)
+	} else if m, ok := canCast[*LoxModule](&v); ok {
+		member, ok := m.members[name]
+		if !ok {
+			return Value{}, fmt.Errorf("module %v has no member %v", m.name, name)
+		}
+		return member, nil
+	}
+	return Value{}, fmt.Errorf("can't access property %v", name)
+}
+
+func (i *Interpreter) VisitListLiteral(l parser.ListLiteral) (any, error) {
+	elements := []Value{}
+	for _, e := range l.Elements {
+		v, err := e.AcceptExpr(i)
+		if err != nil {
+			return nil, err
+		}
+		obj, ok := v.(Value)
+		if !ok {
+			return nil, fmt.Errorf("invalid list element")
+		}
+		elements = append(elements, obj)
+	}
+	if err := i.limits.allocate(len(elements)); err != nil {
+		return nil, err
+	}
+	return toValue(&LoxList{elements: elements}), nil
+}
+
+func (i *Interpreter) VisitIndex(ix parser.Index) (any, error) {
+	v, err := ix.Object.AcceptExpr(i)
+	if err != nil {
+		return nil, err
+	}
+	index, err := ix.Index.AcceptExpr(i)
+	if err != nil {
+		return nil, err
+	}
+
+	if v, ok, err := i.callOperator("__index__", v, index.(Value)); ok || err != nil {
+		return v, err
+	}
+
+	if m, ok := canCast[*LoxMap](&v); ok {
+		value, found, err := m.get(i, index.(Value))
+		if err != nil {
+			return nil, fmt.Errorf("%w, line %v", err, ix.Bracket.Line)
+		} else if !found {
+			return toValue(nil), nil
+		}
+		return value, nil
+	} else if r, ok := canCast[*LoxRange](&index); ok {
+		sliced, err := slice(i.limits, v, r)
+		if err != nil {
+			return nil, fmt.Errorf("%w, line %v", err, ix.Bracket.Line)
+		}
+		return sliced, nil
+	}
+
+	list, ok := canCast[*LoxList](&v)
 	if !ok {
-		return nil, fmt.Errorf("can't find function %v", call.Name)
+		return nil, fmt.Errorf("can't index %v, line %v", stringify(*v.(Value).v), ix.Bracket.Line)
+	}
+	n, err := castTo[int](ix.Bracket, &index)
+	if err != nil {
+		return nil, err
+	}
+	value, err := list.get(n)
+	if err != nil {
+		return nil, fmt.Errorf("%w, line %v", err, ix.Bracket.Line)
 	}
-	fun, ok := getFromLoxObj[LoxFunction](obj)
+	return value, nil
+}
+
+func (i *Interpreter) VisitMapLiteral(l parser.MapLiteral) (any, error) {
+	if err := i.limits.allocate(len(l.Keys)); err != nil {
+		return nil, err
+	}
+	m := newMap()
+	for j := range l.Keys {
+		key, err := l.Keys[j].AcceptExpr(i)
+		if err != nil {
+			return nil, err
+		}
+		value, err := l.Values[j].AcceptExpr(i)
+		if err != nil {
+			return nil, err
+		}
+		if err := m.set(i, key.(Value), value.(Value)); err != nil {
+			return nil, err
+		}
+	}
+	return toValue(m), nil
+}
+
+func (i *Interpreter) VisitIndexAssignmentStatement(a parser.IndexAssignmentStatement) error {
+	target, err := a.Target.Object.AcceptExpr(i)
+	if err != nil {
+		return err
+	}
+	index, err := a.Target.Index.AcceptExpr(i)
+	if err != nil {
+		return err
+	}
+	value, err := a.Expression.AcceptExpr(i)
+	if err != nil {
+		return err
+	}
+
+	line := a.Target.Bracket.Line
+	if m, ok := canCast[*LoxMap](&target); ok {
+		if err := i.limits.allocate(1); err != nil {
+			return err
+		}
+		if err := m.set(i, index.(Value), value.(Value)); err != nil {
+			return fmt.Errorf("%w, line %v", err, line)
+		}
+		return nil
+	}
+
+	list, ok := canCast[*LoxList](&target)
 	if !ok {
-		return nil, fmt.Errorf("%v is not a function", call.Name)
+		return fmt.Errorf("can't assign index of %v, line %v", stringify(*target.(Value).v), line)
 	}
+	n, err := castTo[int](a.Target.Bracket, &index)
+	if err != nil {
+		return err
+	} else if err := list.set(n, value.(Value)); err != nil {
+		return fmt.Errorf("%w, line %v", err, line)
+	}
+	return nil
+}
 
-	scopedEnv := newEnv()
-	for j, arg := range call.Args {
-		v, err := arg.AcceptExpr(i)
+func (i *Interpreter) VisitPropertyAssignmentStatement(a parser.PropertyAssignmentStatement) error {
+	target, err := a.Target.Object.AcceptExpr(i)
+	if err != nil {
+		return err
+	}
+	value, err := a.Expression.AcceptExpr(i)
+	if err != nil {
+		return err
+	}
+
+	line := a.Target.Name.Line
+	if instance, ok := canCast[*LoxInstance](&target); ok {
+		if err := i.limits.allocate(1); err != nil {
+			return err
+		}
+		instance.setField(a.Target.Name.Lexeme, value.(Value))
+		return nil
+	}
+	obj, ok := canCast[*goObject](&target)
+	if !ok {
+		return fmt.Errorf("can't set property %v of %v, line %v", a.Target.Name.Lexeme, stringify(*target.(Value).v), line)
+	}
+	if err := obj.setProperty(a.Target.Name.Lexeme, value.(Value)); err != nil {
+		return fmt.Errorf("%w, line %v", err, line)
+	}
+	return nil
+}
+
+func (i *Interpreter) VisitTryStatement(t parser.TryStatement) error {
+	err := i.blockStatementEval(t.Body, i.env, newEnv())
+	if err == nil || !catchable(err) {
+		return err
+	}
+
+	scopeEnv := newEnv()
+	scopeEnv.create(t.Name, toValue(newLoxError(err)))
+	return i.blockStatementEval(t.Catch, i.env, scopeEnv)
+}
+
+func (i *Interpreter) VisitLetDestructuringStatement(let parser.LetDestructuringStatement) error {
+	return i.doDestructuring(let.DestructuringStatement, func(name string, lo Value) error {
+		i.env.create(name, lo)
+		return nil
+	})
+}
+
+func (i *Interpreter) VisitDestructuringStatement(d parser.DestructuringStatement) error {
+	return i.doDestructuring(d, func(name string, lo Value) error {
+		return i.env.put(name, lo)
+	})
+}
+
+func (i *Interpreter) doDestructuring(d parser.DestructuringStatement, do func(string, Value) error) error {
+	v, err := d.Expression.AcceptExpr(i)
+	if err != nil {
+		return err
+	}
+
+	values := map[string]Value{}
+	if d.Pattern.Object {
+		for _, name := range d.Pattern.Names {
+			prop, err := i.getProperty(v, name)
+			if err != nil {
+				return fmt.Errorf("can't destructure %v: %w", name, err)
+			}
+			values[name] = prop
+		}
+	} else {
+		list, ok := canCast[*LoxList](&v)
+		if !ok {
+			return fmt.Errorf("can't destructure non list value into list pattern")
+		}
+
+		names := d.Pattern.Names
+		elements := list.snapshot()
+		if d.Pattern.Rest == "" && len(elements) != len(names) {
+			return fmt.Errorf("can't destructure list of %d elements into %d variables", len(elements), len(names))
+		} else if len(elements) < len(names) {
+			return fmt.Errorf("can't destructure list of %d elements into at least %d variables", len(elements), len(names))
+		}
+
+		for j, name := range names {
+			values[name] = elements[j]
+		}
+		if d.Pattern.Rest != "" {
+			rest := elements[len(names):]
+			if err := i.limits.allocate(len(rest)); err != nil {
+				return err
+			}
+			values[d.Pattern.Rest] = toValue(&LoxList{elements: rest})
+		}
+	}
+
+	for _, name := range d.Pattern.Names {
+		if err := do(name, values[name]); err != nil {
+			return err
+		}
+	}
+	if d.Pattern.Rest != "" {
+		return do(d.Pattern.Rest, values[d.Pattern.Rest])
+	}
+	return nil
+}
+
+func (i *Interpreter) VisitEnumDeclarationStatement(e parser.EnumDeclaration) error {
+	enum := &LoxEnum{name: e.Name, methods: map[string]LoxFunction{}}
+	for _, v := range e.Variants {
+		if _, exists := enum.variant(v.Name); exists {
+			return fmt.Errorf("duplicated variant %v in enum %v", v.Name, e.Name)
+		}
+
+		variant := &LoxEnumVariant{enum: enum, name: v.Name, fields: v.Fields}
+		if len(v.Fields) == 0 {
+			variant.instance = &LoxEnumValue{variant: variant}
+		}
+		enum.variants = append(enum.variants, variant)
+	}
+	for _, m := range e.Methods {
+		if _, exists := enum.methods[m.Name]; exists {
+			return fmt.Errorf("duplicated method %v in enum %v", m.Name, e.Name)
+		} else if len(m.Args) == 0 {
+			return fmt.Errorf("method %v of enum %v should take the value as first parameter", m.Name, e.Name)
+		}
+		enum.methods[m.Name] = LoxFunction{
+			body:      m.Body,
+			args:      m.Args,
+			generator: parser.ContainsYield(m.Body.Stmts),
+		}
+	}
+	i.env.create(e.Name, toValue(enum))
+	return nil
+}
+
+func (i *Interpreter) VisitClassDeclarationStatement(c parser.ClassDeclaration) error {
+	class := &LoxClass{name: c.Name, methods: map[string]LoxFunction{}}
+	for _, m := range c.Methods {
+		if _, exists := class.methods[m.Name]; exists {
+			return fmt.Errorf("duplicated method %v in class %v", m.Name, c.Name)
+		} else if len(m.Args) == 0 {
+			return fmt.Errorf("method %v of class %v should take the instance as first parameter", m.Name, c.Name)
+		}
+		class.methods[m.Name] = LoxFunction{
+			body:      m.Body,
+			args:      m.Args,
+			generator: parser.ContainsYield(m.Body.Stmts),
+		}
+	}
+	i.env.create(c.Name, toValue(class))
+	return nil
+}
+
+func (i *Interpreter) VisitMatchStatement(m parser.MatchStatement) error {
+	v, err := m.Subject.AcceptExpr(i)
+	if err != nil {
+		return fmt.Errorf("error during evaluating match subject: %w", err)
+	}
+
+	value, ok := canCast[*LoxEnumValue](&v)
+	if !ok {
+		return fmt.Errorf("match subject is not an enum value")
+	}
+	enum := value.variant.enum
+
+	covered := map[string]bool{}
+	for _, c := range m.Cases {
+		variant, ok := enum.variant(c.Variant)
+		if !ok {
+			return fmt.Errorf("enum %v has no variant %v", enum.name, c.Variant)
+		} else if covered[c.Variant] {
+			return fmt.Errorf("duplicated case %v in match on enum %v", c.Variant, enum.name)
+		} else if len(c.Bindings) != len(variant.fields) {
+			return fmt.Errorf("variant %v.%v has %d fields, got %d bindings", enum.name, variant.name, len(variant.fields), len(c.Bindings))
+		}
+		covered[c.Variant] = true
+	}
+
+	if m.Else == nil {
+		missing := []string{}
+		for _, variant := range enum.variants {
+			if !covered[variant.name] {
+				missing = append(missing, variant.name)
+			}
+		}
+		if len(missing) != 0 {
+			return fmt.Errorf("non exhaustive match on enum %v, missing variants: %v", enum.name, strings.Join(missing, ", "))
+		}
+	}
+
+	for _, c := range m.Cases {
+		if c.Variant != value.variant.name {
+			continue
+		}
+
+		scopeEnv := newEnv()
+		for j, binding := range c.Bindings {
+			scopeEnv.create(binding, value.values[j])
+		}
+		return i.blockStatementEval(c.Body, i.env, scopeEnv)
+	}
+	return m.Else.AcceptStatement(i)
+}
+
+func (i *Interpreter) VisitForInStatement(f parser.ForInStatement) error {
+	v, err := f.Iterable.AcceptExpr(i)
+	if err != nil {
+		return fmt.Errorf("error during evaluating for iterable: %w", err)
+	}
+
+	it, err := i.iterate(v)
+	if err != nil {
+		return fmt.Errorf("invalid for statement: %w", err)
+	}
+	defer it.close()
+
+	for {
+		el, ok, err := it.next()
 		if err != nil {
-			return nil, fmt.Errorf("error evaluating args to function %v: %w", call.Name, err)
+			return fmt.Errorf("error during iteration: %w", err)
+		} else if !ok {
+			return nil
+		} else if err := i.limits.step(); err != nil {
+			return err
+		}
+
+		scopeEnv := newEnv()
+		scopeEnv.create(f.Name, el)
+		if err := i.blockStatementEval(f.Body, i.env, scopeEnv); errors.Is(err, errBreak) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("error during processing for block: %w", err)
 		}
-		scopedEnv.create(fun.args[j], v.(LoxObject))
 	}
+}
 
-	if err := i.blockStatementEval(fun.body, i.env, scopedEnv); err != nil {
-		return nil, fmt.Errorf("error during evaluating function %v: %w", call.Name, err)
+func (i *Interpreter) VisitYieldStatement(y parser.YieldStatement) error {
+	if i.generator == nil {
+		return errYieldOutside
+	}
+
+	v, err := y.Expression.AcceptExpr(i)
+	if err != nil {
+		return err
+	}
+	obj, ok := v.(Value)
+	if !ok {
+		return fmt.Errorf("invalid yielded value")
+	}
+	return i.generator.yield(obj)
+}
+
+func (i *Interpreter) VisitBreakStatement(b parser.BreakStatement) error {
+	return fmt.Errorf("%w, line %v", errBreak, b.Line)
+}
+
+func (i *Interpreter) VisitSpawnStatement(s parser.SpawnStatement) error {
+	var name string
+	var callee Value
+	var argExprs []parser.Expression
+	if call, ok := s.Call.(parser.FunctionCall); ok {
+		obj, ok := i.env.get(call.Name)
+		if !ok {
+			return fmt.Errorf("can't find function %v", call.Name)
+		}
+		name, callee, argExprs = call.Name, obj, call.Args
+	} else if call, ok := s.Call.(parser.Call); ok {
+		v, err := call.Callee.AcceptExpr(i)
+		if err != nil {
+			return err
+		}
+		obj, ok := v.(Value)
+		if !ok {
+			return fmt.Errorf("invalid spawn target")
+		}
+		name, callee, argExprs = stringify(*obj.v), obj, call.Args
+	} else {
+		return fmt.Errorf("spawn statement expects a function call")
+	}
+
+	// function and arguments are evaluated in the current goroutine, only the call runs in the new one
+	args, err := i.evaluateArgs(name, argExprs)
+	if err != nil {
+		return err
+	}
+
+	in := i.fork(i.env)
+	in.spawned = true
+	i.goroutines.start()
+	go func() {
+		defer i.goroutines.exit()
+		// functions stopped by the context or by a deadlock are not reported, the main goroutine fails as well
+		if _, err := in.callObject(name, callee, args); err != nil && in.limits.contextErr() == nil && !errors.Is(err, errDeadlock) {
+			fmt.Fprintf(in.stderr, "error in spawned function %v: %v\n", name, err)
+		}
+	}()
+	return nil
+}
+
+func (i *Interpreter) VisitSelectStatement(s parser.SelectStatement) error {
+	ops := []chanOp{}
+	for _, c := range s.Cases {
+		v, err := c.Channel.AcceptExpr(i)
+		if err != nil {
+			return fmt.Errorf("error during evaluating select channel: %w", err)
+		}
+		obj, ok := v.(Value)
+		if !ok {
+			return fmt.Errorf("invalid select channel")
+		}
+		ch, err := toChannel(obj)
+		if err != nil {
+			return err
+		}
+
+		if !c.Send {
+			ops = append(ops, chanOp{ch: ch})
+			continue
+		}
+
+		v, err = c.Value.AcceptExpr(i)
+		if err != nil {
+			return fmt.Errorf("error during evaluating select value: %w", err)
+		}
+		value, ok := v.(Value)
+		if !ok {
+			return fmt.Errorf("invalid select value")
+		}
+		ops = append(ops, chanOp{ch: ch, send: true, value: value})
+	}
+
+	chosen, received, err := i.selectChannels(ops, s.Else != nil)
+	if err != nil {
+		return err
+	} else if chosen == len(s.Cases) {
+		return s.Else.AcceptStatement(i)
+	}
+
+	selected := s.Cases[chosen]
+	scopeEnv := newEnv()
+	if selected.Name != "" {
+		scopeEnv.create(selected.Name, received)
+	}
+	return i.blockStatementEval(selected.Body, i.env, scopeEnv)
+}
+
+func (i *Interpreter) VisitReturnStatement(r parser.ReturnStatement) error {
+	if r.Expression == nil {
+		return returnSignal{value: toValue(nil), line: r.Line}
+	}
+
+	v, err := r.Expression.AcceptExpr(i)
+	if err != nil {
+		return err
+	}
+	obj, ok := v.(Value)
+	if !ok {
+		return fmt.Errorf("invalid returned value, line %v", r.Line)
+	}
+	return returnSignal{value: obj, line: r.Line}
+}
+
+func (i *Interpreter) VisitAsyncFunctionDeclarationStatement(fn parser.AsyncFunctionDeclaration) error {
+	if parser.ContainsYield(fn.Body.Stmts) {
+		return fmt.Errorf("async function %v can't yield", fn.Name)
+	}
+
+	i.env.create(fn.Name, toValue(LoxFunction{
+		body:  fn.Body,
+		args:  fn.Args,
+		async: true,
+	}))
+	return nil
+}
+
+func (i *Interpreter) VisitAwait(a parser.Await) (any, error) {
+	v, err := a.Ex.AcceptExpr(i)
+	if err != nil {
+		return nil, err
+	}
+
+	promise, ok := canCast[*LoxPromise](&v)
+	if !ok {
+		return v, nil
+	}
+	result, err := i.await(promise)
+	if err != nil {
+		return nil, fmt.Errorf("awaited %v failed, line %v: %w", promise.name, a.Keyword.Line, err)
 	}
-	return nil,nil // todo: return statement
+	return result, nil
 }
@@ -4,19 +4,255 @@ import (
 	"fmt"
 	"lox/lexer"
 	"lox/parser"
+	"strings"
+	"sync"
 )
 
-type LoxObject struct {
-	v *any
+type LoxFunction struct {
+	body      parser.BlockStatement
+	args      []string
+	generator bool
+	async     bool
 }
 
-func toLoxObj(v any) LoxObject {
-	return LoxObject{v: &v}
+// nativeFunction is a builtin implemented in Go, with arity Variadic it takes any number of arguments
+type nativeFunction struct {
+	name  string
+	arity int
+	fn    func([]Value) (Value, error)
+	needs Capability
 }
 
-type LoxFunction struct {
-	body parser.BlockStatement
-	args []string
+func (n nativeFunction) String() string {
+	return fmt.Sprintf("<native %v>", n.name)
+}
+
+// LoxList can be shared by goroutines started with spawn, so the elements are guarded by the mutex
+type LoxList struct {
+	mu       sync.RWMutex
+	elements []Value
+}
+
+// at returns the element at index n, false when it's out of range
+func (l *LoxList) at(n int) (Value, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if n < 0 || n >= len(l.elements) {
+		return Value{}, false
+	}
+	return l.elements[n], true
+}
+
+func (l *LoxList) get(n int) (Value, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if n < 0 || n >= len(l.elements) {
+		return Value{}, fmt.Errorf("index %d out of range for list of %d elements", n, len(l.elements))
+	}
+	return l.elements[n], nil
+}
+
+func (l *LoxList) set(n int, value Value) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if n < 0 || n >= len(l.elements) {
+		return fmt.Errorf("index %d out of range for list of %d elements", n, len(l.elements))
+	}
+	l.elements[n] = value
+	return nil
+}
+
+func (l *LoxList) len() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return len(l.elements)
+}
+
+// snapshot returns a copy of the elements
+func (l *LoxList) snapshot() []Value {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return append([]Value{}, l.elements...)
+}
+
+func (l *LoxList) String() string {
+	return l.format(map[any]bool{})
+}
+
+func (l *LoxList) format(seen map[any]bool) string {
+	if seen[l] {
+		return "[...]"
+	}
+	seen[l] = true
+	defer delete(seen, l)
+
+	values := []string{}
+	for _, val := range l.snapshot() {
+		values = append(values, format(*val.v, seen))
+	}
+	return "[" + strings.Join(values, ", ") + "]"
+}
+
+type LoxEnum struct {
+	name     string
+	variants []*LoxEnumVariant
+	methods  map[string]LoxFunction
+}
+
+func (e *LoxEnum) variant(name string) (*LoxEnumVariant, bool) {
+	for _, v := range e.variants {
+		if v.name == name {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func (e *LoxEnum) String() string {
+	return fmt.Sprintf("<enum %v>", e.name)
+}
+
+// LoxEnumVariant is a single variant of an enum. Variants without payload
+// have a single shared instance, so they are compared by identity.
+// Variants with payload act as constructors of new instances
+type LoxEnumVariant struct {
+	enum     *LoxEnum
+	name     string
+	fields   []string
+	instance *LoxEnumValue
+}
+
+func (v *LoxEnumVariant) String() string {
+	return fmt.Sprintf("<variant %v.%v>", v.enum.name, v.name)
+}
+
+type LoxEnumValue struct {
+	variant *LoxEnumVariant
+	values  []Value
+}
+
+func (v *LoxEnumValue) field(name string) (Value, bool) {
+	for i, f := range v.variant.fields {
+		if f == name {
+			return v.values[i], true
+		}
+	}
+	return Value{}, false
+}
+
+// method returns the method of the enum bound to this value
+func (v *LoxEnumValue) method(name string) (boundMethod, bool) {
+	fn, ok := v.variant.enum.methods[name]
+	if !ok {
+		return boundMethod{}, false
+	}
+	return boundMethod{name: v.variant.enum.name + "." + name, self: toValue(v), fn: fn}, true
+}
+
+// methodOwner is a value with methods, an enum value or an instance of a class
+type methodOwner interface {
+	method(name string) (boundMethod, bool)
+}
+
+// boundMethod is a method of an enum or a class with its first parameter set to the value
+type boundMethod struct {
+	name string
+	self Value
+	fn   LoxFunction
+}
+
+func (m boundMethod) String() string {
+	return fmt.Sprintf("<method %v>", m.name)
+}
+
+func (v *LoxEnumValue) String() string {
+	return v.format(map[any]bool{})
+}
+
+func (v *LoxEnumValue) format(seen map[any]bool) string {
+	name := v.variant.enum.name + "." + v.variant.name
+	if len(v.variant.fields) == 0 {
+		return name
+	}
+
+	values := []string{}
+	for _, val := range v.values {
+		values = append(values, format(*val.v, seen))
+	}
+	return fmt.Sprintf("%v(%v)", name, strings.Join(values, ", "))
+}
+
+// LoxClass creates its instances when called
+type LoxClass struct {
+	name    string
+	methods map[string]LoxFunction
+}
+
+func (c *LoxClass) String() string {
+	return fmt.Sprintf("<class %v>", c.name)
+}
+
+// LoxInstance has fields set by assignments to its properties. Instances can
+// be shared by goroutines started with spawn, so the fields are guarded by the mutex
+type LoxInstance struct {
+	class  *LoxClass
+	mu     sync.RWMutex
+	fields map[string]Value
+}
+
+func newInstance(class *LoxClass) *LoxInstance {
+	return &LoxInstance{class: class, fields: map[string]Value{}}
+}
+
+func (o *LoxInstance) field(name string) (Value, bool) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	v, ok := o.fields[name]
+	return v, ok
+}
+
+func (o *LoxInstance) setField(name string, v Value) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.fields[name] = v
+}
+
+// method returns the method of the class bound to this instance
+func (o *LoxInstance) method(name string) (boundMethod, bool) {
+	fn, ok := o.class.methods[name]
+	if !ok {
+		return boundMethod{}, false
+	}
+	return boundMethod{name: o.class.name + "." + name, self: toValue(o), fn: fn}, true
+}
+
+func (o *LoxInstance) String() string {
+	return fmt.Sprintf("<%v instance>", o.class.name)
+}
+
+func isNil(v any) bool {
+	obj, ok := v.(Value)
+	return ok && *obj.v == nil
+}
+
+func stringify(v any) string {
+	return format(v, map[any]bool{})
+}
+
+// format stringifies the value, seen are the lists and maps being formatted,
+// so the ones nested in themselves are printed as [...] and {...}
+func format(v any, seen map[any]bool) string {
+	switch v := v.(type) {
+	case nil:
+		return "nil"
+	case *LoxList:
+		return v.format(seen)
+	case *LoxMap:
+		return v.format(seen)
+	case *LoxEnumValue:
+		return v.format(seen)
+	}
+	return fmt.Sprint(v)
 }
 
 func castTo[T any](t lexer.Token, v *any) (T, error) {
@@ -28,15 +264,19 @@ func castTo[T any](t lexer.Token, v *any) (T, error) {
 }
 
 func canCast[T any](v *any) (T, bool) {
-	loxObj, ok := (*v).(LoxObject)
+	loxObj, ok := (*v).(Value)
 	if !ok {
 		var out T
 		return out, false
 	}
-	return getFromLoxObj[T](loxObj)
+	return getFromValue[T](loxObj)
 }
 
-func getFromLoxObj[T any](loxObj LoxObject) (T, bool) {
+func getFromValue[T any](loxObj Value) (T, bool) {
+	if loxObj.v == nil {
+		var out T
+		return out, false
+	}
 	val, ok := (*loxObj.v).(T)
 	return val, ok
 }
\ No newline at end of file
@@ -0,0 +1,160 @@
+package interpreter
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+func ioNatives(i *Interpreter) []nativeFunction {
+	return []nativeFunction{
+		{name: "readLine", arity: 0, needs: CapStdio, fn: func([]Value) (Value, error) {
+			line, err := i.input().ReadString('\n')
+			if errors.Is(err, io.EOF) && line == "" {
+				return toValue(nil), nil
+			} else if err != nil && !errors.Is(err, io.EOF) {
+				return Value{}, fmt.Errorf("readLine: %w", err)
+			}
+			return i.limits.newString(strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"))
+		}},
+		{name: "readFile", arity: 1, needs: CapFSRead, fn: func(args []Value) (Value, error) {
+			path, err := stringArg("readFile", args[0])
+			if err != nil {
+				return Value{}, err
+			}
+
+			path, err = i.fsPath(path)
+			if err != nil {
+				return Value{}, fmt.Errorf("readFile: %w", err)
+			}
+			// the size is charged before the file is read
+			info, err := os.Stat(path)
+			if err != nil {
+				return Value{}, fmt.Errorf("readFile: %w", err)
+			} else if err := i.limits.allocate(int(info.Size())); err != nil {
+				return Value{}, err
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return Value{}, fmt.Errorf("readFile: %w", err)
+			}
+			return toValue(string(content)), nil
+		}},
+		{name: "writeFile", arity: 2, needs: CapFSWrite, fn: func(args []Value) (Value, error) {
+			path, content, err := twoStringArgs("writeFile", args)
+			if err != nil {
+				return Value{}, err
+			} else if path, err = i.fsPath(path); err != nil {
+				return Value{}, fmt.Errorf("writeFile: %w", err)
+			} else if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				return Value{}, fmt.Errorf("writeFile: %w", err)
+			}
+			return toValue(nil), nil
+		}},
+		{name: "appendFile", arity: 2, needs: CapFSWrite, fn: func(args []Value) (Value, error) {
+			path, content, err := twoStringArgs("appendFile", args)
+			if err != nil {
+				return Value{}, err
+			}
+
+			path, err = i.fsPath(path)
+			if err != nil {
+				return Value{}, fmt.Errorf("appendFile: %w", err)
+			}
+			f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+			if err != nil {
+				return Value{}, fmt.Errorf("appendFile: %w", err)
+			}
+			_, err = f.WriteString(content)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return Value{}, fmt.Errorf("appendFile: %w", err)
+			}
+			return toValue(nil), nil
+		}},
+		{name: "lines", arity: 1, needs: CapFSRead, fn: func(args []Value) (Value, error) {
+			path, err := stringArg("lines", args[0])
+			if err != nil {
+				return Value{}, err
+			}
+			path, err = i.fsPath(path)
+			if err != nil {
+				return Value{}, fmt.Errorf("lines: %w", err)
+			}
+			return toValue(&LoxLines{path: path, limits: i.limits}), nil
+		}},
+		{name: "exists", arity: 1, needs: CapFSRead, fn: func(args []Value) (Value, error) {
+			path, err := stringArg("exists", args[0])
+			if err != nil {
+				return Value{}, err
+			}
+
+			path, err = i.fsPath(path)
+			if err == nil {
+				_, err = os.Stat(path)
+			}
+			if errors.Is(err, os.ErrNotExist) {
+				return toValue(false), nil
+			} else if err != nil {
+				return Value{}, fmt.Errorf("exists: %w", err)
+			}
+			return toValue(true), nil
+		}},
+		{name: "getEnv", arity: 1, needs: CapEnv, fn: func(args []Value) (Value, error) {
+			name, err := stringArg("getEnv", args[0])
+			if err != nil {
+				return Value{}, err
+			}
+
+			value, ok := os.LookupEnv(name)
+			if !ok {
+				return toValue(nil), nil
+			}
+			return i.limits.newString(value)
+		}},
+	}
+}
+
+// LoxLines is an iterable over lines of a file, the file is opened
+// when iteration starts and closed when it ends
+type LoxLines struct {
+	path   string
+	limits *limits
+}
+
+func (l *LoxLines) String() string {
+	return fmt.Sprintf("<lines %v>", l.path)
+}
+
+func (l *LoxLines) iterate() (loxIterator, error) {
+	f, err := os.Open(l.path)
+	if err != nil {
+		return nil, fmt.Errorf("lines: %w", err)
+	}
+	return &linesIterator{file: f, scanner: bufio.NewScanner(f), limits: l.limits}, nil
+}
+
+type linesIterator struct {
+	file    *os.File
+	scanner *bufio.Scanner
+	limits  *limits
+}
+
+func (it *linesIterator) next() (Value, bool, error) {
+	if it.scanner.Scan() {
+		line, err := it.limits.newString(strings.TrimSuffix(it.scanner.Text(), "\r"))
+		return line, err == nil, err
+	} else if err := it.scanner.Err(); err != nil {
+		return Value{}, false, fmt.Errorf("lines: %w", err)
+	}
+	return Value{}, false, nil
+}
+
+func (it *linesIterator) close() {
+	it.file.Close()
+}
@@ -0,0 +1,73 @@
+package interpreter
+
+import "fmt"
+
+// loxIterator is used by for statement to walk over iterable values
+type loxIterator interface {
+	next() (Value, bool, error)
+	close()
+}
+
+// iterate walks over lists, ranges, keys of maps and generators. Enum values and instances are iterable
+// when they have iter() method returning an iterable value or next() method returning nil when exhausted
+func (i *Interpreter) iterate(v any) (loxIterator, error) {
+	if list, ok := canCast[*LoxList](&v); ok {
+		return &listIterator{list: list}, nil
+	} else if r, ok := canCast[*LoxRange](&v); ok {
+		return &rangeIterator{r: r}, nil
+	} else if gen, ok := canCast[*LoxGenerator](&v); ok {
+		return generatorIterator{g: gen, caller: i.generator}, nil
+	} else if m, ok := canCast[*LoxMap](&v); ok {
+		keys, _ := m.entries()
+		return &listIterator{list: &LoxList{elements: keys}}, nil
+	} else if lines, ok := canCast[*LoxLines](&v); ok {
+		return lines.iterate()
+	} else if owner, ok := canCast[methodOwner](&v); ok {
+		if iter, ok := owner.method("iter"); ok {
+			it, err := i.callObject(iter.name, toValue(iter), []Value{})
+			if err != nil {
+				return nil, err
+			} else if self, ok := canCast[methodOwner](&it); !ok || self != owner {
+				return i.iterate(it)
+			}
+		}
+		if next, ok := owner.method("next"); ok {
+			return &methodIterator{in: i, method: next}, nil
+		}
+	}
+	return nil, fmt.Errorf("value is not iterable")
+}
+
+type listIterator struct {
+	list *LoxList
+	idx  int
+}
+
+func (l *listIterator) next() (Value, bool, error) {
+	value, ok := l.list.at(l.idx)
+	if !ok {
+		return Value{}, false, nil
+	}
+	l.idx++
+	return value, true, nil
+}
+
+func (l *listIterator) close() {}
+
+// methodIterator calls next() method of the value until it returns nil
+type methodIterator struct {
+	in     *Interpreter
+	method boundMethod
+}
+
+func (m *methodIterator) next() (Value, bool, error) {
+	v, err := m.in.callObject(m.method.name, toValue(m.method), []Value{})
+	if err != nil {
+		return Value{}, false, err
+	} else if isNil(v) {
+		return Value{}, false, nil
+	}
+	return v.(Value), true, nil
+}
+
+func (m *methodIterator) close() {}
@@ -0,0 +1,221 @@
+package interpreter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+func jsonModule(lim *limits) *LoxModule {
+	return newModule("json", []nativeFunction{
+		{name: "parse", arity: 1, fn: func(args []Value) (Value, error) {
+			text, err := stringArg("json.parse", args[0])
+			if err != nil {
+				return Value{}, err
+			}
+			return parseJSON(lim, text)
+		}},
+		{name: "stringify", arity: 2, fn: func(args []Value) (Value, error) {
+			indent, err := intArg("json.stringify", args[1])
+			if err != nil {
+				return Value{}, err
+			} else if indent < 0 {
+				return Value{}, fmt.Errorf("json.stringify indent should be non negative, got %d", indent)
+			}
+
+			w := &jsonWriter{indent: strings.Repeat(" ", indent), seen: map[any]bool{}}
+			if err := w.write(args[0], 0); err != nil {
+				return Value{}, fmt.Errorf("json.stringify: %w", err)
+			}
+			return lim.newString(w.out.String())
+		}},
+	})
+}
+
+// parseJSON turns objects into maps keeping the order of keys, integers into
+// integers and other numbers into floats. Elements, entries and strings are charged as they are decoded
+func parseJSON(lim *limits, text string) (Value, error) {
+	dec := json.NewDecoder(strings.NewReader(text))
+	dec.UseNumber()
+
+	v, err := decodeJSON(lim, dec)
+	var limit *LimitExceeded
+	if errors.As(err, &limit) {
+		return Value{}, fmt.Errorf("json.parse: %w", err)
+	} else if err != nil {
+		return Value{}, jsonError(text, dec, err)
+	}
+	end := int(dec.InputOffset())
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		end += len(text[end:]) - len(strings.TrimLeft(text[end:], " \t\r\n"))
+		return Value{}, fmt.Errorf("json.parse: %w", newPositionError("unexpected data after JSON value", text, end))
+	}
+	return v, nil
+}
+
+func jsonError(text string, dec *json.Decoder, err error) error {
+	var syntax *json.SyntaxError
+	if errors.As(err, &syntax) {
+		// offset counts the invalid byte too
+		return fmt.Errorf("json.parse: %w", newPositionError(syntax.Error(), text, int(syntax.Offset)-1))
+	} else if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("json.parse: %w", newPositionError("unexpected end of JSON input", text, len(text)))
+	}
+	return fmt.Errorf("json.parse: %w", newPositionError(err.Error(), text, int(dec.InputOffset())))
+}
+
+func decodeJSON(lim *limits, dec *json.Decoder) (Value, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return Value{}, err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		if t == '[' {
+			list := &LoxList{elements: []Value{}}
+			for dec.More() {
+				el, err := decodeJSON(lim, dec)
+				if err != nil {
+					return Value{}, err
+				} else if err := lim.allocate(1); err != nil {
+					return Value{}, err
+				}
+				list.elements = append(list.elements, el)
+			}
+			_, err := dec.Token() // ]
+			return toValue(list), err
+		}
+
+		m := newMap()
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return Value{}, err
+			}
+			value, err := decodeJSON(lim, dec)
+			if err != nil {
+				return Value{}, err
+			} else if err := lim.allocate(1 + len(key.(string))); err != nil {
+				return Value{}, err
+			}
+			// string keys never call __eq__, so the interpreter is not needed
+			if err := m.set(nil, toValue(key.(string)), value); err != nil {
+				return Value{}, err
+			}
+		}
+		_, err := dec.Token() // }
+		return toValue(m), err
+	case json.Number:
+		if n, ok := new(big.Int).SetString(t.String(), 10); ok {
+			return normalizeBigInt(n), nil
+		}
+		f, err := t.Float64()
+		return toValue(f), err
+	case string:
+		return lim.newString(t)
+	}
+	return toValue(tok), nil
+}
+
+type jsonWriter struct {
+	out    strings.Builder
+	indent string
+	seen   map[any]bool
+}
+
+func (w *jsonWriter) newLine(depth int) {
+	if w.indent != "" {
+		w.out.WriteString("\n" + strings.Repeat(w.indent, depth))
+	}
+}
+
+func (w *jsonWriter) write(obj Value, depth int) error {
+	switch v := (*obj.v).(type) {
+	case nil:
+		w.out.WriteString("null")
+	case bool:
+		w.out.WriteString(strconv.FormatBool(v))
+	case int:
+		w.out.WriteString(strconv.Itoa(v))
+	case *big.Int:
+		w.out.WriteString(v.String())
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Errorf("%v can't be represented in JSON", v)
+		}
+		w.out.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+	case string:
+		w.writeString(v)
+	case *LoxList:
+		if w.seen[v] {
+			return fmt.Errorf("cyclic list can't be represented in JSON")
+		}
+		w.seen[v] = true
+		defer delete(w.seen, v)
+
+		elements := v.snapshot()
+		w.out.WriteString("[")
+		for j, el := range elements {
+			if j > 0 {
+				w.out.WriteString(",")
+			}
+			w.newLine(depth + 1)
+			if err := w.write(el, depth+1); err != nil {
+				return err
+			}
+		}
+		if len(elements) > 0 {
+			w.newLine(depth)
+		}
+		w.out.WriteString("]")
+	case *LoxMap:
+		if w.seen[v] {
+			return fmt.Errorf("cyclic map can't be represented in JSON")
+		}
+		w.seen[v] = true
+		defer delete(w.seen, v)
+
+		keys, values := v.entries()
+		w.out.WriteString("{")
+		for j, k := range keys {
+			key, ok := getFromValue[string](k)
+			if !ok {
+				return fmt.Errorf("JSON object keys should be strings, got %v", stringify(*k.v))
+			}
+			if j > 0 {
+				w.out.WriteString(",")
+			}
+			w.newLine(depth + 1)
+			w.writeString(key)
+			w.out.WriteString(":")
+			if w.indent != "" {
+				w.out.WriteString(" ")
+			}
+			if err := w.write(values[j], depth+1); err != nil {
+				return err
+			}
+		}
+		if len(keys) > 0 {
+			w.newLine(depth)
+		}
+		w.out.WriteString("}")
+	default:
+		return fmt.Errorf("%v can't be represented in JSON", stringify(v))
+	}
+	return nil
+}
+
+func (w *jsonWriter) writeString(s string) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	w.out.WriteString(strings.TrimSuffix(buf.String(), "\n"))
+}
@@ -0,0 +1,121 @@
+package interpreter
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Limit names what stopped the script
+type Limit string
+
+const (
+	LimitContext     Limit = "context"
+	LimitSteps       Limit = "steps"
+	LimitCallDepth   Limit = "call depth"
+	LimitAllocations Limit = "allocations"
+)
+
+// defaultMaxCallDepth keeps deep recursion from overflowing the Go stack
+const defaultMaxCallDepth = 2000
+
+// LimitExceeded stops the script when its context is done or it runs out of
+// a budget set by options. It can't be caught by try
+type LimitExceeded struct {
+	Limit Limit
+	// Err is the error of the context for LimitContext
+	Err error
+}
+
+func (e *LimitExceeded) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("limit exceeded: %v: %v", e.Limit, e.Err)
+	}
+	return fmt.Sprintf("limit exceeded: %v", e.Limit)
+}
+
+func (e *LimitExceeded) Unwrap() error {
+	return e.Err
+}
+
+// limits are shared by the interpreter and its goroutines, zero maximum means no limit.
+// Steps are statements, loop iterations and calls. Allocations are roughly counted as
+// list elements, map entries and bytes of built strings
+type limits struct {
+	ctx            context.Context
+	maxSteps       int64
+	steps          int64
+	maxCallDepth   int
+	maxAllocations int64
+	allocations    int64
+}
+
+func newLimits() *limits {
+	return &limits{ctx: context.Background(), maxCallDepth: defaultMaxCallDepth}
+}
+
+func (l *limits) contextErr() error {
+	if err := l.ctx.Err(); err != nil {
+		return &LimitExceeded{Limit: LimitContext, Err: err}
+	}
+	return nil
+}
+
+func (l *limits) step() error {
+	if err := l.contextErr(); err != nil {
+		return err
+	} else if l.maxSteps > 0 && atomic.AddInt64(&l.steps, 1) > l.maxSteps {
+		return &LimitExceeded{Limit: LimitSteps}
+	}
+	return nil
+}
+
+func (l *limits) allocate(n int) error {
+	if l.maxAllocations > 0 && atomic.AddInt64(&l.allocations, int64(n)) > l.maxAllocations {
+		return &LimitExceeded{Limit: LimitAllocations}
+	}
+	return nil
+}
+
+// newString charges the bytes of a string built by a native
+func (l *limits) newString(s string) (Value, error) {
+	if err := l.allocate(len(s)); err != nil {
+		return Value{}, err
+	}
+	return toValue(s), nil
+}
+
+// sleep waits on the clock, waiting for the real time stops when the context is done
+func (l *limits) sleep(c Clock, d time.Duration) error {
+	if _, ok := c.(realClock); !ok || l.ctx.Done() == nil {
+		c.Sleep(d)
+		return l.contextErr()
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-l.ctx.Done():
+		return l.contextErr()
+	}
+}
+
+// enterCall counts the step and depth of a call, leaveCall has to follow it
+func (i *Interpreter) enterCall() error {
+	if err := i.limits.step(); err != nil {
+		return err
+	}
+	i.depth++
+	if i.limits.maxCallDepth > 0 && i.depth > i.limits.maxCallDepth {
+		i.depth--
+		return &LimitExceeded{Limit: LimitCallDepth}
+	}
+	return nil
+}
+
+func (i *Interpreter) leaveCall() {
+	i.depth--
+}
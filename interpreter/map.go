@@ -0,0 +1,222 @@
+package interpreter
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+)
+
+// LoxMap keeps keys in insertion order. Strings, numbers, booleans and nil
+// are looked up by value, other keys are compared like ==, so enum
+// values with __eq__ method can be used as keys. Maps can be shared by
+// goroutines started with spawn, so the entries are guarded by the mutex
+type LoxMap struct {
+	mu     sync.RWMutex
+	keys   []Value
+	values []Value
+	index  map[any]int
+	// version changes when keys are added or removed, so positions found
+	// without holding the lock can be checked before they are used
+	version uint64
+}
+
+func newMap() *LoxMap {
+	return &LoxMap{index: map[any]int{}}
+}
+
+// hashKey returns key of the index for simple values
+func hashKey(key Value) (any, bool) {
+	switch v := (*key.v).(type) {
+	case nil, string, bool, int:
+		return v, true
+	case float64:
+		// 1.0 and 1 are the same key, like 1.0 == 1
+		if v == math.Trunc(v) && v >= math.MinInt && v < math.MaxInt {
+			return int(v), true
+		}
+		return v, true
+	}
+	return nil, false
+}
+
+// find returns the position of the key and the version it is valid for. Other keys
+// are compared without holding the lock, as __eq__ methods may use the map too
+func (m *LoxMap) find(i *Interpreter, key Value) (int, uint64, error) {
+	m.mu.RLock()
+	version := m.version
+	if h, ok := hashKey(key); ok {
+		idx, ok := m.index[h]
+		m.mu.RUnlock()
+		if !ok {
+			idx = -1
+		}
+		return idx, version, nil
+	}
+	keys := append([]Value{}, m.keys...)
+	m.mu.RUnlock()
+
+	for idx, k := range keys {
+		if eq, err := i.equals(k, key); err != nil || eq {
+			return idx, version, err
+		}
+	}
+	return -1, version, nil
+}
+
+func (m *LoxMap) get(i *Interpreter, key Value) (Value, bool, error) {
+	for {
+		idx, version, err := m.find(i, key)
+		if err != nil || idx < 0 {
+			return Value{}, false, err
+		}
+
+		m.mu.RLock()
+		if m.version == version {
+			value := m.values[idx]
+			m.mu.RUnlock()
+			return value, true, nil
+		}
+		m.mu.RUnlock()
+	}
+}
+
+func (m *LoxMap) set(i *Interpreter, key, value Value) error {
+	for {
+		idx, version, err := m.find(i, key)
+		if err != nil {
+			return err
+		}
+
+		m.mu.Lock()
+		if m.version != version {
+			m.mu.Unlock()
+			continue
+		}
+		if idx >= 0 {
+			m.values[idx] = value
+		} else {
+			if h, ok := hashKey(key); ok {
+				m.index[h] = len(m.keys)
+			}
+			m.keys = append(m.keys, key)
+			m.values = append(m.values, value)
+			m.version++
+		}
+		m.mu.Unlock()
+		return nil
+	}
+}
+
+// remove deletes the key and returns its value, nil when key was missing
+func (m *LoxMap) remove(i *Interpreter, key Value) (Value, error) {
+	for {
+		idx, version, err := m.find(i, key)
+		if err != nil || idx < 0 {
+			return toValue(nil), err
+		}
+
+		m.mu.Lock()
+		if m.version != version {
+			m.mu.Unlock()
+			continue
+		}
+		value := m.values[idx]
+		m.keys = append(m.keys[:idx], m.keys[idx+1:]...)
+		m.values = append(m.values[:idx], m.values[idx+1:]...)
+		m.index = map[any]int{}
+		for j, k := range m.keys {
+			if h, ok := hashKey(k); ok {
+				m.index[h] = j
+			}
+		}
+		m.version++
+		m.mu.Unlock()
+		return value, nil
+	}
+}
+
+// property returns the value of the string key, used for m.key access
+func (m *LoxMap) property(name string) (Value, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	idx, ok := m.index[name]
+	if !ok {
+		return Value{}, false
+	}
+	return m.values[idx], true
+}
+
+// entries returns copies of the keys and the values
+func (m *LoxMap) entries() ([]Value, []Value) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]Value{}, m.keys...), append([]Value{}, m.values...)
+}
+
+func (m *LoxMap) len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.keys)
+}
+
+func (m *LoxMap) String() string {
+	return m.format(map[any]bool{})
+}
+
+func (m *LoxMap) format(seen map[any]bool) string {
+	if seen[m] {
+		return "{...}"
+	}
+	seen[m] = true
+	defer delete(seen, m)
+
+	keys, values := m.entries()
+	entries := []string{}
+	for j, k := range keys {
+		entries = append(entries, format(*k.v, seen)+": "+format(*values[j].v, seen))
+	}
+	return "{" + strings.Join(entries, ", ") + "}"
+}
+
+func mapNatives(i *Interpreter) []nativeFunction {
+	toMap := func(name string, obj Value) (*LoxMap, error) {
+		m, ok := getFromValue[*LoxMap](obj)
+		if !ok {
+			return nil, fmt.Errorf("%v expects map, got %v", name, stringify(*obj.v))
+		}
+		return m, nil
+	}
+
+	return []nativeFunction{
+		{name: "keys", arity: 1, fn: func(args []Value) (Value, error) {
+			m, err := toMap("keys", args[0])
+			if err != nil {
+				return Value{}, err
+			}
+			keys, _ := m.entries()
+			if err := i.limits.allocate(len(keys)); err != nil {
+				return Value{}, err
+			}
+			return toValue(&LoxList{elements: keys}), nil
+		}},
+		{name: "values", arity: 1, fn: func(args []Value) (Value, error) {
+			m, err := toMap("values", args[0])
+			if err != nil {
+				return Value{}, err
+			}
+			_, values := m.entries()
+			if err := i.limits.allocate(len(values)); err != nil {
+				return Value{}, err
+			}
+			return toValue(&LoxList{elements: values}), nil
+		}},
+		{name: "remove", arity: 2, fn: func(args []Value) (Value, error) {
+			m, err := toMap("remove", args[0])
+			if err != nil {
+				return Value{}, err
+			}
+			return m.remove(i, args[1])
+		}},
+	}
+}
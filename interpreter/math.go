@@ -0,0 +1,257 @@
+package interpreter
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// LoxModule is a namespace of natives and constants, its members are accessed like math.sqrt
+type LoxModule struct {
+	name    string
+	members map[string]Value
+}
+
+func newModule(name string, natives []nativeFunction) *LoxModule {
+	m := &LoxModule{name: name, members: map[string]Value{}}
+	for _, native := range natives {
+		member := native.name
+		native.name = name + "." + member
+		m.members[member] = toValue(native)
+	}
+	return m
+}
+
+func (m *LoxModule) String() string {
+	return fmt.Sprintf("<module %v>", m.name)
+}
+
+func mathModule(lim *limits) *LoxModule {
+	m := newModule("math", []nativeFunction{
+		{name: "sqrt", arity: 1, fn: func(args []Value) (Value, error) {
+			x, err := floatArg("math.sqrt", args[0])
+			if err != nil {
+				return Value{}, err
+			} else if x < 0 {
+				return Value{}, fmt.Errorf("math.sqrt of negative number %v", x)
+			}
+			return toValue(math.Sqrt(x)), nil
+		}},
+		{name: "pow", arity: 2, fn: func(args []Value) (Value, error) {
+			base, isInt := toBigInt(args[0])
+			exp, isIntExp := toBigInt(args[1])
+			if isInt && isIntExp && exp.Sign() >= 0 {
+				// 0, 1 and -1 stay small, otherwise the result has at least (bits of base - 1) * exp bits
+				if base.BitLen() > 1 {
+					bits := new(big.Int).Mul(big.NewInt(int64(base.BitLen()-1)), exp)
+					if !bits.IsInt64() {
+						bits.SetInt64(math.MaxInt64)
+					}
+					if err := lim.allocateBigInt(bits.Int64()); err != nil {
+						return Value{}, fmt.Errorf("math.pow: %w", err)
+					}
+				}
+				return normalizeBigInt(new(big.Int).Exp(base, exp, nil)), nil
+			}
+
+			x, err := floatArg("math.pow", args[0])
+			if err != nil {
+				return Value{}, err
+			}
+			y, err := floatArg("math.pow", args[1])
+			if err != nil {
+				return Value{}, err
+			}
+			return toValue(math.Pow(x, y)), nil
+		}},
+		{name: "abs", arity: 1, fn: func(args []Value) (Value, error) {
+			if b, ok := toBigInt(args[0]); ok {
+				return normalizeBigInt(new(big.Int).Abs(b)), nil
+			}
+			x, err := floatArg("math.abs", args[0])
+			return toValue(math.Abs(x)), err
+		}},
+		rounding("floor", math.Floor),
+		rounding("ceil", math.Ceil),
+		rounding("round", math.Round),
+		extremum("min", -1),
+		extremum("max", 1),
+		floatFunction("sin", math.Sin),
+		floatFunction("cos", math.Cos),
+		floatFunction("tan", math.Tan),
+		floatFunction("asin", math.Asin),
+		floatFunction("acos", math.Acos),
+		floatFunction("atan", math.Atan),
+		{name: "atan2", arity: 2, fn: func(args []Value) (Value, error) {
+			y, err := floatArg("math.atan2", args[0])
+			if err != nil {
+				return Value{}, err
+			}
+			x, err := floatArg("math.atan2", args[1])
+			return toValue(math.Atan2(y, x)), err
+		}},
+		{name: "gcd", arity: 2, fn: func(args []Value) (Value, error) {
+			a, b, err := twoBigIntArgs("math.gcd", args)
+			if err != nil {
+				return Value{}, err
+			}
+			return normalizeBigInt(gcd(a, b)), nil
+		}},
+		{name: "lcm", arity: 2, fn: func(args []Value) (Value, error) {
+			a, b, err := twoBigIntArgs("math.lcm", args)
+			if err != nil {
+				return Value{}, err
+			}
+
+			d := gcd(a, b)
+			if d.Sign() == 0 {
+				return toValue(0), nil
+			}
+			lcm := new(big.Int).Mul(a, b)
+			return normalizeBigInt(lcm.Abs(lcm.Quo(lcm, d))), nil
+		}},
+	})
+	m.members["pi"] = toValue(math.Pi)
+	m.members["e"] = toValue(math.E)
+	return m
+}
+
+func gcd(a, b *big.Int) *big.Int {
+	return new(big.Int).GCD(nil, nil, new(big.Int).Abs(a), new(big.Int).Abs(b))
+}
+
+func floatFunction(name string, fn func(float64) float64) nativeFunction {
+	return nativeFunction{name: name, arity: 1, fn: func(args []Value) (Value, error) {
+		x, err := floatArg("math."+name, args[0])
+		if err != nil {
+			return Value{}, err
+		}
+		return toValue(fn(x)), nil
+	}}
+}
+
+// rounding functions return integers
+func rounding(name string, fn func(float64) float64) nativeFunction {
+	return nativeFunction{name: name, arity: 1, fn: func(args []Value) (Value, error) {
+		if _, ok := toBigInt(args[0]); ok {
+			return args[0], nil
+		}
+		x, err := floatArg("math."+name, args[0])
+		if err != nil {
+			return Value{}, err
+		}
+		return floatToInt(fn(x))
+	}}
+}
+
+// extremum returns the argument which compares to the other one with sign
+func extremum(name string, sign int) nativeFunction {
+	return nativeFunction{name: name, arity: 2, fn: func(args []Value) (Value, error) {
+		cmp := 0
+		a, aInt := toBigInt(args[0])
+		b, bInt := toBigInt(args[1])
+		if aInt && bInt {
+			cmp = a.Cmp(b)
+		} else {
+			x, err := floatArg("math."+name, args[0])
+			if err != nil {
+				return Value{}, err
+			}
+			y, err := floatArg("math."+name, args[1])
+			if err != nil {
+				return Value{}, err
+			}
+			if x < y {
+				cmp = -1
+			} else if x > y {
+				cmp = 1
+			}
+		}
+
+		if cmp == -sign {
+			return args[1], nil
+		}
+		return args[0], nil
+	}}
+}
+
+func floatArg(name string, obj Value) (float64, error) {
+	f, ok := toFloat(obj)
+	if !ok {
+		return 0, fmt.Errorf("%v expects number, got %v", name, stringify(*obj.v))
+	}
+	return f, nil
+}
+
+func twoBigIntArgs(name string, args []Value) (*big.Int, *big.Int, error) {
+	a, ok := toBigInt(args[0])
+	if !ok {
+		return nil, nil, fmt.Errorf("%v expects integer, got %v", name, stringify(*args[0].v))
+	}
+	b, ok := toBigInt(args[1])
+	if !ok {
+		return nil, nil, fmt.Errorf("%v expects integer, got %v", name, stringify(*args[1].v))
+	}
+	return a, b, nil
+}
+
+// lockedRand is the random source of the interpreter, shared by its goroutines.
+// Sequences after seed(n) are reproducible
+type lockedRand struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+func newLockedRand() *lockedRand {
+	return &lockedRand{r: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+func randomModule(random *lockedRand) *LoxModule {
+	return newModule("random", []nativeFunction{
+		{name: "seed", arity: 1, fn: func(args []Value) (Value, error) {
+			seed, err := intArg("random.seed", args[0])
+			if err != nil {
+				return Value{}, err
+			}
+
+			random.mu.Lock()
+			defer random.mu.Unlock()
+			random.r = rand.New(rand.NewSource(int64(seed)))
+			return toValue(nil), nil
+		}},
+		{name: "randInt", arity: 2, fn: func(args []Value) (Value, error) {
+			a, err := intArg("random.randInt", args[0])
+			if err != nil {
+				return Value{}, err
+			}
+			b, err := intArg("random.randInt", args[1])
+			if err != nil {
+				return Value{}, err
+			} else if a > b {
+				return Value{}, fmt.Errorf("random.randInt empty range from %d to %d", a, b)
+			}
+
+			random.mu.Lock()
+			defer random.mu.Unlock()
+			// a + Int63n(b - a + 1) could overflow, the span is drawn as big integer
+			span := new(big.Int).Sub(big.NewInt(int64(b)), big.NewInt(int64(a)))
+			n := new(big.Int).Rand(random.r, span.Add(span, big.NewInt(1)))
+			return normalizeBigInt(n.Add(n, big.NewInt(int64(a)))), nil
+		}},
+		{name: "choice", arity: 1, fn: func(args []Value) (Value, error) {
+			elements, ok := args[0].AsList()
+			if !ok {
+				return Value{}, fmt.Errorf("random.choice expects list, got %v", stringify(*args[0].v))
+			} else if len(elements) == 0 {
+				return Value{}, fmt.Errorf("random.choice from empty list")
+			}
+
+			random.mu.Lock()
+			defer random.mu.Unlock()
+			return elements[random.r.Intn(len(elements))], nil
+		}},
+	})
+}
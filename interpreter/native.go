@@ -0,0 +1,50 @@
+package interpreter
+
+import (
+	"fmt"
+	"lox/typecheck"
+)
+
+// NativeFunc is a function implemented in Go, callable from Lox like any other function
+type NativeFunc func(args []Value) (Value, error)
+
+// Variadic arity lets a native function take any number of arguments
+const Variadic = -1
+
+// Define registers a global native function. Arity is checked before the call,
+// unless it is Variadic
+func (i *Interpreter) Define(name string, arity int, fn NativeFunc) {
+	i.globals.create(name, toValue(nativeFunction{name: name, arity: arity, fn: fn}))
+	i.declare(name, typecheck.Function)
+}
+
+// IntArg converts an argument of the native function called name, the error is ready to be returned from it
+func IntArg(name string, v Value) (int, error) {
+	return intArg(name, v)
+}
+
+// FloatArg accepts integers as well
+func FloatArg(name string, v Value) (float64, error) {
+	return floatArg(name, v)
+}
+
+func StringArg(name string, v Value) (string, error) {
+	return stringArg(name, v)
+}
+
+func BoolArg(name string, v Value) (bool, error) {
+	b, ok := getFromValue[bool](v)
+	if !ok {
+		return false, fmt.Errorf("%v expects boolean, got %v", name, stringify(*v.v))
+	}
+	return b, nil
+}
+
+// ListArg returns elements of the list, changing them doesn't change the list
+func ListArg(name string, v Value) ([]Value, error) {
+	l, ok := getFromValue[*LoxList](v)
+	if !ok {
+		return nil, fmt.Errorf("%v expects list, got %v", name, stringify(*v.v))
+	}
+	return l.snapshot(), nil
+}
@@ -0,0 +1,136 @@
+package interpreter
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// methods of enums and classes overloading operators
+var (
+	unaryMethods = map[string]string{
+		"-": "__neg__",
+		"!": "__not__",
+	}
+	binaryMethods = map[string]string{
+		"+":  "__add__",
+		"-":  "__sub__",
+		"*":  "__mul__",
+		"/":  "__div__",
+		"%":  "__mod__",
+		"==": "__eq__",
+		"!=": "__eq__",
+		"<":  "__lt__",
+		"<=": "__le__",
+		">":  "__gt__",
+		">=": "__ge__",
+	}
+	// reflectedMethods are called on the right operand when the left one doesn't overload the operator
+	reflectedMethods = map[string]string{
+		"==": "__eq__",
+		"!=": "__eq__",
+		"<":  "__gt__",
+		"<=": "__ge__",
+		">":  "__lt__",
+		">=": "__le__",
+	}
+)
+
+// callOperator calls the method of the enum value or the instance overloading an operator,
+// the bool result is false if the value doesn't have such method
+func (i *Interpreter) callOperator(method string, self any, args ...Value) (any, bool, error) {
+	owner, ok := canCast[methodOwner](&self)
+	if !ok || method == "" {
+		return nil, false, nil
+	}
+	m, ok := owner.method(method)
+	if !ok {
+		return nil, false, nil
+	}
+
+	v, err := i.callObject(m.name, toValue(m), args)
+	return v, true, err
+}
+
+func (i *Interpreter) binaryOperator(op string, left, right any) (any, bool, error) {
+	v, ok, err := i.callOperator(binaryMethods[op], left, right.(Value))
+	if !ok && err == nil {
+		v, ok, err = i.callOperator(reflectedMethods[op], right, left.(Value))
+	}
+	if !ok || err != nil || op != "!=" {
+		return v, ok, err
+	}
+
+	eq, isBool := canCast[bool](&v)
+	if !isBool {
+		return nil, true, fmt.Errorf("__eq__ should return bool, got %v", stringify(*v.(Value).v))
+	}
+	return toValue(!eq), true, nil
+}
+
+// equals compares values like == does, honoring __eq__ of enums and classes.
+// Unlike ==, values of different types are not equal instead of being an error
+func (i *Interpreter) equals(a, b Value) (bool, error) {
+	v, ok, err := i.binaryOperator("==", a, b)
+	if err != nil {
+		return false, err
+	} else if ok {
+		eq, isBool := getFromValue[bool](v.(Value))
+		if !isBool {
+			return false, fmt.Errorf("__eq__ should return bool, got %v", stringify(*v.(Value).v))
+		}
+		return eq, nil
+	}
+
+	if x, ok := toBigInt(a); ok {
+		if y, ok := toBigInt(b); ok {
+			return x.Cmp(y) == 0, nil
+		}
+	}
+	if x, ok := toFloat(a); ok {
+		if y, ok := toFloat(b); ok {
+			return x == y, nil
+		}
+	}
+
+	x, y := *a.v, *b.v
+	if x == nil || y == nil {
+		return x == nil && y == nil, nil
+	}
+	typ := reflect.TypeOf(x)
+	return typ == reflect.TypeOf(y) && typ.Comparable() && x == y, nil
+}
+
+// contains implements `in` operator: substrings of strings, numbers of ranges,
+// keys of maps and elements of any iterable value
+func (i *Interpreter) contains(container, v Value) (bool, error) {
+	if r, ok := getFromValue[*LoxRange](container); ok {
+		n, ok := getFromValue[int](v)
+		return ok && r.contains(n), nil
+	} else if m, ok := getFromValue[*LoxMap](container); ok {
+		idx, _, err := m.find(i, v)
+		return idx >= 0, err
+	} else if str, ok := getFromValue[string](container); ok {
+		sub, ok := getFromValue[string](v)
+		if !ok {
+			return false, fmt.Errorf("only strings can be searched in string")
+		}
+		return strings.Contains(str, sub), nil
+	}
+
+	it, err := i.iterate(container)
+	if err != nil {
+		return false, err
+	}
+	defer it.close()
+
+	for {
+		el, ok, err := it.next()
+		if err != nil || !ok {
+			return false, err
+		}
+		if eq, err := i.equals(el, v); err != nil || eq {
+			return eq, err
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package interpreter
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"sync"
+)
+
+type Option func(*Interpreter)
+
+// WithClock sets the clock used by timers, by default it's the real time
+func WithClock(c Clock) Option {
+	return func(i *Interpreter) {
+		i.loop.clock = c
+	}
+}
+
+// WithStdout sets the writer of print, by default it's os.Stdout
+func WithStdout(w io.Writer) Option {
+	return func(i *Interpreter) {
+		i.stdout = &syncWriter{w: w}
+	}
+}
+
+// WithStderr sets the writer of errors of spawned functions, by default it's os.Stderr
+func WithStderr(w io.Writer) Option {
+	return func(i *Interpreter) {
+		i.stderr = &syncWriter{w: w}
+	}
+}
+
+// WithStdin sets the reader of readLine, by default it's os.Stdin
+func WithStdin(r io.Reader) Option {
+	return func(i *Interpreter) {
+		i.stdin = bufio.NewReader(r)
+	}
+}
+
+// WithContext stops the script with LimitExceeded when the context is done.
+// Waiting for timers, sleep and channels stops as well
+func WithContext(ctx context.Context) Option {
+	return func(i *Interpreter) {
+		i.limits.ctx = ctx
+	}
+}
+
+// WithMaxSteps limits the number of executed statements, loop iterations and calls
+func WithMaxSteps(n int) Option {
+	return func(i *Interpreter) {
+		i.limits.maxSteps = int64(n)
+	}
+}
+
+// WithMaxCallDepth limits nesting of calls, by default it's 2000. Zero removes the limit
+func WithMaxCallDepth(n int) Option {
+	return func(i *Interpreter) {
+		i.limits.maxCallDepth = n
+	}
+}
+
+// WithMaxAllocations limits the number of list elements, map entries and bytes of strings built by the script
+func WithMaxAllocations(n int) Option {
+	return func(i *Interpreter) {
+		i.limits.maxAllocations = int64(n)
+	}
+}
+
+// WithCapabilities grants natives of the standard library access to resources, by default it's CapAll
+func WithCapabilities(c Capability) Option {
+	return func(i *Interpreter) {
+		i.capabilities = c
+	}
+}
+
+// WithFSRoot makes file natives treat paths as relative to the root, they can't reach files outside of it
+func WithFSRoot(root string) Option {
+	return func(i *Interpreter) {
+		i.fsRoot = root
+	}
+}
+
+// WithGlobal defines a global variable, e.g. an input of a program run with lox.Run
+func WithGlobal(name string, v Value) Option {
+	return func(i *Interpreter) {
+		if i.optionGlobals == nil {
+			i.optionGlobals = map[string]Value{}
+		}
+		if v.v == nil {
+			v = toValue(nil)
+		}
+		i.optionGlobals[name] = v
+	}
+}
+
+// syncWriter lets goroutines of the interpreter share a writer, which isn't safe for concurrent use
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
@@ -0,0 +1,128 @@
+package interpreter
+
+import (
+	"fmt"
+	"lox/lexer"
+)
+
+// LoxRange is a lazy sequence of integers from start to end by step,
+// the end is included unless the range was created with ..<
+type LoxRange struct {
+	start     int
+	end       int
+	step      int
+	inclusive bool
+}
+
+func newRange(op lexer.Token, start, end any) (*LoxRange, error) {
+	s, err := castTo[int](op, &start)
+	if err != nil {
+		return nil, fmt.Errorf("range bounds should be integers: %w", err)
+	}
+	e, err := castTo[int](op, &end)
+	if err != nil {
+		return nil, fmt.Errorf("range bounds should be integers: %w", err)
+	}
+	return &LoxRange{start: s, end: e, step: 1, inclusive: op.Lexeme == ".."}, nil
+}
+
+func (r *LoxRange) len() int {
+	last := r.end
+	if !r.inclusive && r.step > 0 {
+		last--
+	} else if !r.inclusive {
+		last++
+	}
+
+	if (r.step > 0 && last < r.start) || (r.step < 0 && last > r.start) {
+		return 0
+	}
+	return (last-r.start)/r.step + 1
+}
+
+// at returns k-th element of the range
+func (r *LoxRange) at(k int) int {
+	return r.start + k*r.step
+}
+
+func (r *LoxRange) contains(n int) bool {
+	k := (n - r.start) / r.step
+	return (n-r.start)%r.step == 0 && k >= 0 && k < r.len()
+}
+
+func (r *LoxRange) property(name string) (Value, error) {
+	if name != "step" {
+		return Value{}, fmt.Errorf("range has no property %v", name)
+	}
+	return toValue(nativeFunction{name: "step", arity: 1, fn: func(args []Value) (Value, error) {
+		step, ok := getFromValue[int](args[0])
+		if !ok || step == 0 {
+			return Value{}, fmt.Errorf("range step should be non zero integer")
+		}
+		return toValue(&LoxRange{start: r.start, end: r.end, step: step, inclusive: r.inclusive}), nil
+	}}), nil
+}
+
+func (r *LoxRange) String() string {
+	op := ".."
+	if !r.inclusive {
+		op = "..<"
+	}
+	if r.step == 1 {
+		return fmt.Sprintf("%d%v%d", r.start, op, r.end)
+	}
+	return fmt.Sprintf("(%d%v%d).step(%d)", r.start, op, r.end, r.step)
+}
+
+type rangeIterator struct {
+	r   *LoxRange
+	idx int
+}
+
+func (it *rangeIterator) next() (Value, bool, error) {
+	if it.idx >= it.r.len() {
+		return Value{}, false, nil
+	}
+	it.idx++
+	return toValue(it.r.at(it.idx - 1)), true, nil
+}
+
+func (it *rangeIterator) close() {}
+
+// slice returns elements of the list or characters of the string at indexes from the range
+func slice(lim *limits, v any, r *LoxRange) (Value, error) {
+	var size int
+	var element func(int) Value
+	if list, ok := canCast[*LoxList](&v); ok {
+		elements := list.snapshot()
+		size = len(elements)
+		element = func(k int) Value { return elements[k] }
+	} else if str, ok := canCast[string](&v); ok {
+		chars := []rune(str)
+		size = len(chars)
+		element = func(k int) Value { return toValue(string(chars[k])) }
+	} else {
+		return Value{}, fmt.Errorf("can't slice %v", stringify(*v.(Value).v))
+	}
+
+	elements := []Value{}
+	for k := 0; k < r.len(); k++ {
+		idx := r.at(k)
+		if idx < 0 || idx >= size {
+			return Value{}, fmt.Errorf("slice %v out of range for length %d", r, size)
+		}
+		elements = append(elements, element(idx))
+	}
+	if err := lim.allocate(len(elements)); err != nil {
+		return Value{}, err
+	}
+
+	if _, ok := canCast[string](&v); ok {
+		out := ""
+		for _, e := range elements {
+			out += (*e.v).(string)
+		}
+		return toValue(out), nil
+	}
+	return toValue(&LoxList{elements: elements}), nil
+}
@@ -0,0 +1,221 @@
+package interpreter
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// stringNatives work on characters, not bytes, so indexes and lengths
+// of strings with multibyte characters are the same as in chars(s)
+func stringNatives(i *Interpreter) []nativeFunction {
+	return []nativeFunction{
+		{name: "len", arity: 1, fn: func(args []Value) (Value, error) {
+			if s, ok := getFromValue[string](args[0]); ok {
+				return toValue(utf8.RuneCountInString(s)), nil
+			} else if list, ok := getFromValue[*LoxList](args[0]); ok {
+				return toValue(list.len()), nil
+			} else if r, ok := getFromValue[*LoxRange](args[0]); ok {
+				return toValue(r.len()), nil
+			} else if m, ok := getFromValue[*LoxMap](args[0]); ok {
+				return toValue(m.len()), nil
+			}
+			return Value{}, fmt.Errorf("len expects string, list, range or map, got %v", stringify(*args[0].v))
+		}},
+		{name: "substr", arity: 3, fn: func(args []Value) (Value, error) {
+			s, err := stringArg("substr", args[0])
+			if err != nil {
+				return Value{}, err
+			}
+			start, err := intArg("substr", args[1])
+			if err != nil {
+				return Value{}, err
+			}
+			length, err := intArg("substr", args[2])
+			if err != nil {
+				return Value{}, err
+			}
+
+			chars := []rune(s)
+			if start < 0 || length < 0 || start > len(chars) || length > len(chars)-start {
+				return Value{}, fmt.Errorf("substr of %d characters from %d is out of range for string of length %d", length, start, len(chars))
+			}
+			return i.limits.newString(string(chars[start : start+length]))
+		}},
+		{name: "indexOf", arity: 2, fn: func(args []Value) (Value, error) {
+			s, sub, err := twoStringArgs("indexOf", args)
+			if err != nil {
+				return Value{}, err
+			}
+
+			idx := strings.Index(s, sub)
+			if idx < 0 {
+				return toValue(-1), nil
+			}
+			return toValue(utf8.RuneCountInString(s[:idx])), nil
+		}},
+		{name: "split", arity: 2, fn: func(args []Value) (Value, error) {
+			s, sep, err := twoStringArgs("split", args)
+			if err != nil {
+				return Value{}, err
+			}
+			return stringList(i.limits, strings.Split(s, sep))
+		}},
+		{name: "join", arity: 2, fn: func(args []Value) (Value, error) {
+			list, ok := getFromValue[*LoxList](args[0])
+			if !ok {
+				return Value{}, fmt.Errorf("join expects list, got %v", stringify(*args[0].v))
+			}
+			sep, err := stringArg("join", args[1])
+			if err != nil {
+				return Value{}, err
+			}
+
+			values := []string{}
+			size := 0
+			for j, el := range list.snapshot() {
+				values = append(values, stringify(*el.v))
+				if j > 0 {
+					size += len(sep)
+				}
+				size += len(values[j])
+			}
+			if err := i.limits.allocate(size); err != nil {
+				return Value{}, err
+			}
+			return toValue(strings.Join(values, sep)), nil
+		}},
+		stringMapper(i, "trim", strings.TrimSpace),
+		stringMapper(i, "upper", strings.ToUpper),
+		stringMapper(i, "lower", strings.ToLower),
+		{name: "replace", arity: 3, fn: func(args []Value) (Value, error) {
+			s, old, err := twoStringArgs("replace", args)
+			if err != nil {
+				return Value{}, err
+			}
+			replacement, err := stringArg("replace", args[2])
+			if err != nil {
+				return Value{}, err
+			}
+
+			// the result is charged before it's built, replacing may multiply its length
+			n := strings.Count(s, old)
+			if len(replacement) > 0 && n > (math.MaxInt-len(s))/len(replacement) {
+				return Value{}, fmt.Errorf("replace result is too long")
+			} else if err := i.limits.allocate(len(s) + n*(len(replacement)-len(old))); err != nil {
+				return Value{}, err
+			}
+			return toValue(strings.ReplaceAll(s, old, replacement)), nil
+		}},
+		{name: "startsWith", arity: 2, fn: func(args []Value) (Value, error) {
+			s, prefix, err := twoStringArgs("startsWith", args)
+			if err != nil {
+				return Value{}, err
+			}
+			return toValue(strings.HasPrefix(s, prefix)), nil
+		}},
+		{name: "repeat", arity: 2, fn: func(args []Value) (Value, error) {
+			s, err := stringArg("repeat", args[0])
+			if err != nil {
+				return Value{}, err
+			}
+			n, err := intArg("repeat", args[1])
+			if err != nil {
+				return Value{}, err
+			} else if n < 0 {
+				return Value{}, fmt.Errorf("repeat count should be non negative, got %d", n)
+			} else if n > 0 && len(s) > math.MaxInt/n {
+				return Value{}, fmt.Errorf("repeat result is too long")
+			} else if err := i.limits.allocate(len(s) * n); err != nil {
+				return Value{}, err
+			}
+			return toValue(strings.Repeat(s, n)), nil
+		}},
+		{name: "chars", arity: 1, fn: func(args []Value) (Value, error) {
+			s, err := stringArg("chars", args[0])
+			if err != nil {
+				return Value{}, err
+			}
+
+			chars := []string{}
+			for _, r := range s {
+				chars = append(chars, string(r))
+			}
+			return stringList(i.limits, chars)
+		}},
+		{name: "str", arity: 1, fn: func(args []Value) (Value, error) {
+			return i.limits.newString(stringify(*args[0].v))
+		}},
+		{name: "num", arity: 1, fn: func(args []Value) (Value, error) {
+			if _, ok := toFloat(args[0]); ok {
+				return args[0], nil
+			}
+			s, err := stringArg("num", args[0])
+			if err != nil {
+				return Value{}, err
+			}
+
+			if n, ok := new(big.Int).SetString(strings.TrimSpace(s), 10); ok {
+				return normalizeBigInt(n), nil
+			} else if f, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil {
+				return toValue(f), nil
+			}
+			return Value{}, fmt.Errorf("num can't convert %q to number", s)
+		}},
+	}
+}
+
+func stringMapper(i *Interpreter, name string, fn func(string) string) nativeFunction {
+	return nativeFunction{name: name, arity: 1, fn: func(args []Value) (Value, error) {
+		s, err := stringArg(name, args[0])
+		if err != nil {
+			return Value{}, err
+		}
+		return i.limits.newString(fn(s))
+	}}
+}
+
+// stringList charges the elements of the list and the bytes of the strings
+func stringList(lim *limits, values []string) (Value, error) {
+	size := len(values)
+	for _, v := range values {
+		size += len(v)
+	}
+	if err := lim.allocate(size); err != nil {
+		return Value{}, err
+	}
+
+	elements := []Value{}
+	for _, v := range values {
+		elements = append(elements, toValue(v))
+	}
+	return toValue(&LoxList{elements: elements}), nil
+}
+
+func stringArg(name string, obj Value) (string, error) {
+	s, ok := getFromValue[string](obj)
+	if !ok {
+		return "", fmt.Errorf("%v expects string, got %v", name, stringify(*obj.v))
+	}
+	return s, nil
+}
+
+func twoStringArgs(name string, args []Value) (string, string, error) {
+	a, err := stringArg(name, args[0])
+	if err != nil {
+		return "", "", err
+	}
+	b, err := stringArg(name, args[1])
+	return a, b, err
+}
+
+func intArg(name string, obj Value) (int, error) {
+	n, ok := getFromValue[int](obj)
+	if !ok {
+		return 0, fmt.Errorf("%v expects integer, got %v", name, stringify(*obj.v))
+	}
+	return n, nil
+}
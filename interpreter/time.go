@@ -0,0 +1,69 @@
+package interpreter
+
+import (
+	"fmt"
+	"time"
+
+	// time zones work on systems without the tz database too
+	_ "time/tzdata"
+)
+
+// timeNatives read the clock of the event loop, so with a virtual clock
+// timestamps are deterministic and sleep returns immediately.
+// Timestamps are milliseconds since the Unix epoch, clock() gives seconds since the interpreter was created
+func timeNatives(i *Interpreter) []nativeFunction {
+	start := i.loop.clock.Now()
+	return []nativeFunction{
+		{name: "clock", arity: 0, needs: CapClock, fn: func([]Value) (Value, error) {
+			return toValue(i.loop.clock.Now().Sub(start).Seconds()), nil
+		}},
+		{name: "now", arity: 0, needs: CapClock, fn: func([]Value) (Value, error) {
+			return toValue(int(i.loop.clock.Now().UnixMilli())), nil
+		}},
+		{name: "sleep", arity: 1, needs: CapClock, fn: func(args []Value) (Value, error) {
+			ms, err := intArg("sleep", args[0])
+			if err != nil {
+				return Value{}, err
+			} else if ms < 0 {
+				return Value{}, fmt.Errorf("sleep duration should be non negative, got %d", ms)
+			}
+			return toValue(nil), i.limits.sleep(i.loop.clock, time.Duration(ms)*time.Millisecond)
+		}},
+		{name: "formatDate", arity: 3, fn: func(args []Value) (Value, error) {
+			ms, err := intArg("formatDate", args[0])
+			if err != nil {
+				return Value{}, err
+			}
+			layout, zone, err := twoStringArgs("formatDate", args[1:])
+			if err != nil {
+				return Value{}, err
+			}
+			loc, err := time.LoadLocation(zone)
+			if err != nil {
+				return Value{}, fmt.Errorf("formatDate: %w", err)
+			}
+			return i.limits.newString(time.UnixMilli(int64(ms)).In(loc).Format(layout))
+		}},
+		{name: "parseDate", arity: 3, fn: func(args []Value) (Value, error) {
+			text, layout, err := twoStringArgs("parseDate", args)
+			if err != nil {
+				return Value{}, err
+			}
+			zone, err := stringArg("parseDate", args[2])
+			if err != nil {
+				return Value{}, err
+			}
+			loc, err := time.LoadLocation(zone)
+			if err != nil {
+				return Value{}, fmt.Errorf("parseDate: %w", err)
+			}
+
+			// zone is used only when the text has no offset
+			t, err := time.ParseInLocation(layout, text, loc)
+			if err != nil {
+				return Value{}, fmt.Errorf("parseDate: %w", err)
+			}
+			return toValue(int(t.UnixMilli())), nil
+		}},
+	}
+}
@@ -0,0 +1,300 @@
+package interpreter
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+)
+
+// Kind is the tag of a Value
+type Kind int
+
+const (
+	KindNil Kind = iota
+	KindBool
+	// KindInt values are Go ints or *big.Int when they don't fit
+	KindInt
+	KindFloat
+	KindString
+	KindList
+	KindMap
+	KindRange
+	// KindFunction covers Lox and native functions, methods and enum variants
+	KindFunction
+	KindEnum
+	// KindOther are generators, channels, promises, modules, errors, instances of classes and other builtin objects
+	KindOther
+)
+
+func (k Kind) String() string {
+	return [...]string{
+		"nil",
+		"bool",
+		"int",
+		"float",
+		"string",
+		"list",
+		"map",
+		"range",
+		"function",
+		"enum",
+		"other",
+	}[k]
+}
+
+// Value is a Lox value. Zero Value is nil
+type Value struct {
+	kind Kind
+	v    *any
+}
+
+func toValue(v any) Value {
+	return Value{kind: kindOf(v), v: &v}
+}
+
+func kindOf(v any) Kind {
+	switch v.(type) {
+	case nil:
+		return KindNil
+	case bool:
+		return KindBool
+	case int, *big.Int:
+		return KindInt
+	case float64:
+		return KindFloat
+	case string:
+		return KindString
+	case *LoxList:
+		return KindList
+	case *LoxMap:
+		return KindMap
+	case *LoxRange:
+		return KindRange
+	case LoxFunction, nativeFunction, boundMethod, *LoxEnumVariant, *LoxClass:
+		return KindFunction
+	case *LoxEnum, *LoxEnumValue:
+		return KindEnum
+	}
+	return KindOther
+}
+
+func (o Value) Kind() Kind {
+	return o.kind
+}
+
+// IsNil reports whether the value is nil, zero Value is nil as well
+func (o Value) IsNil() bool {
+	return o.kind == KindNil
+}
+
+// String formats the value like print does
+func (o Value) String() string {
+	if o.v == nil {
+		return "nil"
+	}
+	return stringify(*o.v)
+}
+
+func Nil() Value {
+	return toValue(nil)
+}
+
+func NewInt(n int) Value {
+	return toValue(n)
+}
+
+// NewBigInt keeps the integer as int when it fits
+func NewBigInt(n *big.Int) Value {
+	return normalizeBigInt(n)
+}
+
+func NewFloat(f float64) Value {
+	return toValue(f)
+}
+
+func NewString(s string) Value {
+	return toValue(s)
+}
+
+func NewBool(b bool) Value {
+	return toValue(b)
+}
+
+func NewList(values ...Value) Value {
+	return toValue(&LoxList{elements: append([]Value{}, values...)})
+}
+
+func (o Value) AsBool() (bool, bool) {
+	return getFromValue[bool](o)
+}
+
+// AsInt fails for integers which don't fit into int, see AsBigInt
+func (o Value) AsInt() (int, bool) {
+	return getFromValue[int](o)
+}
+
+func (o Value) AsBigInt() (*big.Int, bool) {
+	if o.kind != KindInt {
+		return nil, false
+	}
+	return toBigInt(o)
+}
+
+// AsFloat converts integers as well
+func (o Value) AsFloat() (float64, bool) {
+	if o.kind != KindInt && o.kind != KindFloat {
+		return 0, false
+	}
+	return toFloat(o)
+}
+
+func (o Value) AsString() (string, bool) {
+	return getFromValue[string](o)
+}
+
+// AsList returns elements of the list, changing them doesn't change the list
+func (o Value) AsList() ([]Value, bool) {
+	l, ok := getFromValue[*LoxList](o)
+	if !ok {
+		return nil, false
+	}
+	return l.snapshot(), true
+}
+
+// AsMap returns keys and values of the map in its order
+func (o Value) AsMap() ([]Value, []Value, bool) {
+	m, ok := getFromValue[*LoxMap](o)
+	if !ok {
+		return nil, nil, false
+	}
+	keys, values := m.entries()
+	return keys, values, true
+}
+
+// FromGo converts nil, booleans, integers, floats, strings, *big.Int, slices,
+// arrays and maps to Lox values. Values are kept as they are, pointers to structs
+// are bound like with Bind (nil ones are nil) and map keys have to be nil, booleans, numbers or strings
+func FromGo(v any) (Value, error) {
+	switch val := v.(type) {
+	case nil:
+		return toValue(nil), nil
+	case Value:
+		// the zero Value is nil, like a result of a native function
+		if val.v == nil {
+			return toValue(nil), nil
+		}
+		return val, nil
+	case Function:
+		return val.obj, nil
+	case *big.Int:
+		return normalizeBigInt(val), nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Bool:
+		return toValue(rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return normalizeBigInt(big.NewInt(rv.Int())), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return normalizeBigInt(new(big.Int).SetUint64(rv.Uint())), nil
+	case reflect.Float32, reflect.Float64:
+		return toValue(rv.Float()), nil
+	case reflect.String:
+		return toValue(rv.String()), nil
+	case reflect.Slice, reflect.Array:
+		elements := []Value{}
+		for j := 0; j < rv.Len(); j++ {
+			el, err := FromGo(rv.Index(j).Interface())
+			if err != nil {
+				return Value{}, err
+			}
+			elements = append(elements, el)
+		}
+		return toValue(&LoxList{elements: elements}), nil
+	case reflect.Map:
+		m := newMap()
+		iter := rv.MapRange()
+		for iter.Next() {
+			key, err := FromGo(iter.Key().Interface())
+			if err != nil {
+				return Value{}, err
+			}
+			if _, ok := hashKey(key); !ok {
+				return Value{}, fmt.Errorf("can't use %v as a map key", key)
+			}
+			value, err := FromGo(iter.Value().Interface())
+			if err != nil {
+				return Value{}, err
+			}
+			// keys with hashKey are never compared with methods, so no interpreter is needed
+			if err := m.set(nil, key, value); err != nil {
+				return Value{}, err
+			}
+		}
+		return toValue(m), nil
+	case reflect.Pointer:
+		if rv.Type().Elem().Kind() == reflect.Struct {
+			if rv.IsNil() {
+				return toValue(nil), nil
+			}
+			return toValue(&goObject{ptr: rv}), nil
+		}
+	}
+	return Value{}, fmt.Errorf("can't convert %T to lox value", v)
+}
+
+// ToGo converts the value to nil, bool, int, *big.Int, float64, string, []any or
+// map[string]any (map[any]any when some key is not a string). Bound Go structs are
+// returned as the pointers and other values as they are
+func ToGo(v Value) any {
+	return toGo(v, func(v Value) any { return v })
+}
+
+// toGo converts the value like ToGo, values other than Go types are converted with other
+func toGo(obj Value, other func(Value) any) any {
+	if obj.v == nil {
+		return nil
+	}
+	switch val := (*obj.v).(type) {
+	case bool, int, float64, string:
+		return val
+	case nil:
+		return nil
+	case *big.Int:
+		// ints are never big.Int, see normalizeBigInt
+		return val
+	case *LoxList:
+		out := []any{}
+		for _, el := range val.snapshot() {
+			out = append(out, toGo(el, other))
+		}
+		return out
+	case *LoxMap:
+		return mapToGo(val, other)
+	case *goObject:
+		return val.ptr.Interface()
+	}
+	return other(obj)
+}
+
+func mapToGo(m *LoxMap, other func(Value) any) any {
+	keys, values := m.entries()
+	strKeys := map[string]any{}
+	for j, k := range keys {
+		s, ok := getFromValue[string](k)
+		if !ok {
+			out := map[any]any{}
+			for j, k := range keys {
+				key := toGo(k, other)
+				if key != nil && !reflect.TypeOf(key).Comparable() {
+					key = stringify(key)
+				}
+				out[key] = toGo(values[j], other)
+			}
+			return out
+		}
+		strKeys[s] = toGo(values[j], other)
+	}
+	return strKeys
+}
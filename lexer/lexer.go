@@ -2,6 +2,7 @@ package lexer
 
 import (
 	"fmt"
+	"strings"
 	"unicode"
 )
 
@@ -18,7 +19,9 @@ const (
 	StringLiteral
 	Semicolon
 	Comma
-	null
+	Dot
+	Colon
+	Nil
 )
 
 func (t TokenType) String() string {
@@ -33,7 +36,9 @@ func (t TokenType) String() string {
 		"stringLiteral",
 		"semicolon",
 		"comma",
-		"null",
+		"dot",
+		"colon",
+		"nil",
 	}[t]
 }
 
@@ -47,6 +52,16 @@ func (t Token) String() string {
 	return fmt.Sprintf("(%v, %v)", t.TokType, t.Lexeme)
 }
 
+// Error is an invalid character sequence at the line, Message mentions the line as well
+type Error struct {
+	Line    int
+	Message string
+}
+
+func (e Error) Error() string {
+	return e.Message
+}
+
 func CheckToken(tok Token, tokType TokenType, lexeme string) bool {
 	return CheckTokenType(tok, tokType) && tok.Lexeme == lexeme
 }
@@ -56,7 +71,11 @@ func CheckTokenType(tok Token, tokType TokenType) bool {
 }
 
 func isKeyword(word string) bool {
-	return word == "let" || word == "while" || word == "return" || word == "else" || word == "if" || word == "function"
+	return word == "let" || word == "while" || word == "return" || word == "else" || word == "if" || word == "function" ||
+		word == "enum" || word == "class" || word == "match" || word == "case" ||
+		word == "for" || word == "in" || word == "yield" || word == "break" ||
+		word == "spawn" || word == "select" || word == "async" || word == "await" ||
+		word == "try" || word == "catch"
 }
 
 func Lex(input string) ([]Token, error) {
@@ -86,13 +105,28 @@ func Lex(input string) ([]Token, error) {
 			if current == '\n' {
 				lineNumer++
 			}
-		} else if current == ')' || current == '}' {
+		} else if current == ')' || current == '}' || current == ']' {
 			addTok(Closing, string(current))
 		} else if current == ';' {
 			addTok(Semicolon, string(current))
 		} else if current == ',' {
 			addTok(Comma, string(current))
-		} else if current == '(' || current == '{' {
+		} else if current == ':' {
+			addTok(Colon, string(current))
+		} else if current == '.' {
+			if strings.HasPrefix(input[idx:], "...") {
+				idx += 2
+				addTok(Operator, "...")
+			} else if strings.HasPrefix(input[idx:], "..<") {
+				idx += 2
+				addTok(Operator, "..<")
+			} else if strings.HasPrefix(input[idx:], "..") {
+				idx++
+				addTok(Operator, "..")
+			} else {
+				addTok(Dot, string(current))
+			}
+		} else if current == '(' || current == '{' || current == '[' {
 			addTok(Opening, string(current))
 		} else if current == '+' || current == '-' || current == '*' || current == '/' || current == '%' {
 			addTok(Operator, string(current))
@@ -108,22 +142,24 @@ func Lex(input string) ([]Token, error) {
 				idx++
 				addTok(Operator, string(current)+string(next))
 			} else {
-				return nil, fmt.Errorf("invalid boolean operator on line %d", lineNumer)
+				return nil, Error{Line: lineNumer, Message: fmt.Sprintf("invalid boolean operator on line %d", lineNumer)}
 			}
 		} else if current == '"' {
-			idx++
-			word := readUntil(input, &idx, func(r rune) bool { return r != '"' })
-			if next, ok := peek(); ok && next == '"' {
-				idx++
-				addTok(StringLiteral, word)
-			} else {
-				return nil, fmt.Errorf("invalid token at line %d: \"%s\"", lineNumer, word)
+			word, ok := readString(input, &idx)
+			if !ok {
+				return nil, Error{Line: lineNumer, Message: fmt.Sprintf("invalid token at line %d: \"%s\"", lineNumer, word)}
 			}
+			addTok(StringLiteral, word)
 		} else if unicode.IsDigit(current) {
 			num := readUntil(input, &idx, unicode.IsDigit)
+			// fraction needs a digit after the dot, so 1..10 stays a range
+			if next, ok := peek(); ok && next == '.' && idx+2 < len(input) && unicode.IsDigit(rune(input[idx+2])) {
+				idx += 2
+				num += "." + readUntil(input, &idx, unicode.IsDigit)
+			}
 			addTok(Number, num)
 		} else {
-			word := readUntil(input, &idx, unicode.IsLetter)
+			word := readUntil(input, &idx, isIdentifierChar)
 			tokType := classifyWord(word)
 			addTok(tokType, word)
 		}
@@ -132,28 +168,57 @@ func Lex(input string) ([]Token, error) {
 	return out, nil
 }
 
+func isIdentifierChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
 func classifyWord(word string) TokenType {
 	if isKeyword(word) {
 		return Keyword
 	} else if word == "true" || word == "false" {
 		return Boolean
-	} else if word == "null" {
-		return null
+	} else if word == "nil" {
+		return Nil
 	}
 	return Identifier
 }
 
-func readUntil(input string, idx *int, fn func(rune) bool) string {
-	out := ""
-	out += string(input[*idx])
+// readString reads string literal from the opening quote at idx up to the closing one,
+// replacing escape sequences \" \\ \n and \t. Other backslashes are kept as they are
+func readString(input string, idx *int) (string, bool) {
+	var out strings.Builder
 	for *idx+1 < len(input) {
-		next := input[*idx+1]
-		if fn(rune(next)) {
-			*idx++
-			out += string(next)
-		} else {
-			break
+		*idx++
+		c := input[*idx]
+		if c == '"' {
+			return out.String(), true
+		} else if c != '\\' || *idx+1 >= len(input) {
+			out.WriteByte(c)
+			continue
+		}
+
+		*idx++
+		switch input[*idx] {
+		case 'n':
+			out.WriteByte('\n')
+		case 't':
+			out.WriteByte('\t')
+		case '"', '\\':
+			out.WriteByte(input[*idx])
+		default:
+			out.WriteByte('\\')
+			out.WriteByte(input[*idx])
 		}
 	}
-	return out
+	return out.String(), false
+}
+
+// readUntil reads from idx while fn holds for the following bytes, multibyte
+// characters are kept intact as their bytes never match ASCII characters
+func readUntil(input string, idx *int, fn func(rune) bool) string {
+	start := *idx
+	for *idx+1 < len(input) && fn(rune(input[*idx+1])) {
+		*idx++
+	}
+	return input[start : *idx+1]
 }
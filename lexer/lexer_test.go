@@ -21,6 +21,14 @@ func TestLex(t *testing.T) {
 				{TokType: Identifier, Lexeme: "ife"},
 			},
 		},
+		{
+			desc:  "identifiers with underscores and digits",
+			input: `__add__ point2d`,
+			expected: []Token{
+				{TokType: Identifier, Lexeme: "__add__"},
+				{TokType: Identifier, Lexeme: "point2d"},
+			},
+		},
 		{
 			desc:  "number",
 			input: ` 1234;`,
@@ -29,6 +37,19 @@ func TestLex(t *testing.T) {
 				{TokType: Semicolon, Lexeme: ";"},
 			},
 		},
+		{
+			desc:  "fractions",
+			input: `3.14 1..2 x.y`,
+			expected: []Token{
+				{TokType: Number, Lexeme: "3.14"},
+				{TokType: Number, Lexeme: "1"},
+				{TokType: Operator, Lexeme: ".."},
+				{TokType: Number, Lexeme: "2"},
+				{TokType: Identifier, Lexeme: "x"},
+				{TokType: Dot, Lexeme: "."},
+				{TokType: Identifier, Lexeme: "y"},
+			},
+		},
 		{
 			desc:  "whitespaces",
 			input: " \t \n 123\t",
@@ -53,6 +74,21 @@ o	"	`,
 				{TokType: Number, Lexeme: "123"},
 			},
 		},
+		{
+			desc:  "empty and unicode strings",
+			input: `"" "zażółć 日本"`,
+			expected: []Token{
+				{TokType: StringLiteral, Lexeme: ""},
+				{TokType: StringLiteral, Lexeme: "zażółć 日本"},
+			},
+		},
+		{
+			desc:  "escape sequences",
+			input: `"say \"hi\"\n\ttab \\ \d"`,
+			expected: []Token{
+				{TokType: StringLiteral, Lexeme: "say \"hi\"\n\ttab \\ \\d"},
+			},
+		},
 		{
 			desc:  "operators",
 			input: `= == < <= > >= ! !! != || &&`,
@@ -71,6 +107,48 @@ o	"	`,
 				{TokType: Operator, Lexeme: "&&"},
 			},
 		},
+		{
+			desc:  "enum variant access",
+			input: `enum Color { Red } Color.Red`,
+			expected: []Token{
+				{TokType: Keyword, Lexeme: "enum"},
+				{TokType: Identifier, Lexeme: "Color"},
+				{TokType: Opening, Lexeme: "{"},
+				{TokType: Identifier, Lexeme: "Red"},
+				{TokType: Closing, Lexeme: "}"},
+				{TokType: Identifier, Lexeme: "Color"},
+				{TokType: Dot, Lexeme: "."},
+				{TokType: Identifier, Lexeme: "Red"},
+			},
+		},
+		{
+			desc:  "destructuring",
+			input: `let [a, ...rest] = xs;`,
+			expected: []Token{
+				{TokType: Keyword, Lexeme: "let"},
+				{TokType: Opening, Lexeme: "["},
+				{TokType: Identifier, Lexeme: "a"},
+				{TokType: Comma, Lexeme: ","},
+				{TokType: Operator, Lexeme: "..."},
+				{TokType: Identifier, Lexeme: "rest"},
+				{TokType: Closing, Lexeme: "]"},
+				{TokType: Operator, Lexeme: "="},
+				{TokType: Identifier, Lexeme: "xs"},
+				{TokType: Semicolon, Lexeme: ";"},
+			},
+		},
+		{
+			desc:  "ranges",
+			input: `1..10 0..<n`,
+			expected: []Token{
+				{TokType: Number, Lexeme: "1"},
+				{TokType: Operator, Lexeme: ".."},
+				{TokType: Number, Lexeme: "10"},
+				{TokType: Number, Lexeme: "0"},
+				{TokType: Operator, Lexeme: "..<"},
+				{TokType: Identifier, Lexeme: "n"},
+			},
+		},
 		{
 			desc:  "operators without spaces",
 			input: `==<<=>>=||&&!!!!=`,
@@ -114,4 +192,9 @@ func TestInvalidInput(t *testing.T) {
 		assert.Error(t, err)
 		assert.Equal(t, "invalid token at line 1: \" hello world \"", err.Error())
 	})
+
+	t.Run("unterminated quote", func(t *testing.T) {
+		_, err := Lex(`let s = "`)
+		assert.Error(t, err)
+	})
 }
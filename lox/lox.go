@@ -0,0 +1,59 @@
+// Package lox compiles Lox source into programs and runs them. Errors found
+// before running, in every phase, are reported as Diagnostics
+package lox
+
+import (
+	"context"
+	"errors"
+	"lox/compiler"
+	"lox/interpreter"
+	"lox/parser"
+	"lox/typecheck"
+)
+
+// Phase is the step of compilation which found the problem
+type Phase = compiler.Phase
+
+const (
+	PhaseLex   = compiler.PhaseLex
+	PhaseParse = compiler.PhaseParse
+	PhaseType  = compiler.PhaseType
+)
+
+// Diagnostic is a problem found while compiling the source called Name, Line is 0 when it's unknown
+type Diagnostic = compiler.Diagnostic
+
+// Program is a compiled source ready to run. It's immutable, so one program can be
+// run by many interpreters at once, each of them with its own globals
+type Program struct {
+	name  string
+	stmts []parser.Statement
+}
+
+func (p *Program) Name() string {
+	return p.name
+}
+
+// Compile lexes, parses and type checks the source. The program is nil when there are diagnostics
+func Compile(name, src string) (*Program, []Diagnostic) {
+	stmts, diagnostics := compiler.Compile(name, src, typecheck.NewChecker())
+	if diagnostics != nil {
+		return nil, diagnostics
+	}
+	return &Program{name: name, stmts: stmts}, nil
+}
+
+// Run runs the program in a new interpreter with the options and returns the value
+// of its last expression statement. Cancelling the context stops the program.
+// The context of the interpreter is cancelled when Run returns, so generators
+// left suspended and spawned functions still running are stopped
+func Run(ctx context.Context, p *Program, opts ...interpreter.Option) (interpreter.Value, error) {
+	if p == nil {
+		return interpreter.Value{}, errors.New("run of nil program")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	opts = append(append([]interpreter.Option{}, opts...), interpreter.WithContext(ctx))
+	in := interpreter.NewInterpreter(opts...)
+	return in.Run(p.stmts)
+}
@@ -0,0 +1,150 @@
+package lox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"lox/interpreter"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompile(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		src      string
+		expected []Diagnostic
+	}{
+		{
+			desc: "lexer error",
+			src:  "let x = 1;\nlet y = \"abc;",
+			expected: []Diagnostic{
+				{Name: "test.lox", Phase: PhaseLex, Line: 2, Message: `invalid token at line 2: "abc;"`},
+			},
+		},
+		{
+			desc: "parser errors",
+			src:  "let x = ;\nlet y = 1;\nlet = 2;",
+			expected: []Diagnostic{
+				{Name: "test.lox", Phase: PhaseParse, Line: 1, Message: "unexpected token when parsing primary expression, at line 1 at token (semicolon, ;)"},
+				{Name: "test.lox", Phase: PhaseParse, Line: 3, Message: "expected identifier, at line 3 at token (operator, =)"},
+			},
+		},
+		{
+			desc: "type errors",
+			src:  "let x: number = \"a\";\nlet y: string = 1;",
+			expected: []Diagnostic{
+				{Name: "test.lox", Phase: PhaseType, Line: 1, Message: "can't assign string to x of type number"},
+				{Name: "test.lox", Phase: PhaseType, Line: 2, Message: "can't assign number to y of type string"},
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			program, diagnostics := Compile("test.lox", tC.src)
+			assert.Nil(t, program)
+			assert.Equal(t, tC.expected, diagnostics)
+		})
+	}
+
+	t.Run("error format", func(t *testing.T) {
+		_, diagnostics := Compile("test.lox", "let x: number = \"a\";")
+		require.Len(t, diagnostics, 1)
+		assert.Equal(t, "test.lox:1: type error: can't assign string to x of type number", diagnostics[0].Error())
+	})
+}
+
+func TestRun(t *testing.T) {
+	program, diagnostics := Compile("test.lox", `let x = 40; print(x); x + 2;`)
+	require.Empty(t, diagnostics)
+	assert.Equal(t, "test.lox", program.Name())
+
+	var out bytes.Buffer
+	got, err := Run(context.Background(), program, interpreter.WithStdout(&out))
+	require.NoError(t, err)
+	assert.Equal(t, "42", got.String())
+	assert.Equal(t, "40\n", out.String())
+
+	program, diagnostics = Compile("loop.lox", `while (true) {}`)
+	require.Empty(t, diagnostics)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = Run(ctx, program)
+	var limit *interpreter.LimitExceeded
+	assert.True(t, errors.As(err, &limit))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	_, err = Run(context.Background(), nil)
+	assert.EqualError(t, err, "run of nil program")
+}
+
+func TestRunConcurrently(t *testing.T) {
+	program, diagnostics := Compile("rules.lox", `
+enum Tier { Basic, Gold(discount) }
+function tier(total) {
+	if (total > 100) { return Tier.Gold(10); }
+	return Tier.Basic;
+}
+function price(order) {
+	let total = 0;
+	for (item in order["items"]) { total = total + item; }
+	let t = tier(total);
+	match (t) {
+		case Gold(discount) { total = total - discount; }
+		case Basic {}
+	}
+	return {"total": total, "label": upper(order["name"])};
+}
+let counter = 0;
+function next() { counter = counter + 1; yield counter; }
+for (c in next()) {}
+price(input);
+`)
+	require.Empty(t, diagnostics)
+
+	results := make(chan error)
+	for n := 0; n < 50; n++ {
+		go func(n int) {
+			input, err := interpreter.FromGo(map[string]any{"name": "order", "items": []int{n, n, n}})
+			if err != nil {
+				results <- err
+				return
+			}
+			got, err := Run(context.Background(), program, interpreter.WithGlobal("input", input))
+			if err != nil {
+				results <- err
+				return
+			}
+
+			expected := 3 * n
+			if expected > 100 {
+				expected -= 10
+			}
+			if g := interpreter.ToGo(got); !assert.ObjectsAreEqual(map[string]any{"total": expected, "label": "ORDER"}, g) {
+				results <- fmt.Errorf("input %d: got %v", n, g)
+				return
+			}
+			results <- nil
+		}(n)
+	}
+	for n := 0; n < 50; n++ {
+		assert.NoError(t, <-results)
+	}
+}
+
+// BenchmarkRun shows the cost of a run, each one creates a new interpreter with the standard library
+func BenchmarkRun(b *testing.B) {
+	program, diagnostics := Compile("bench.lox", `let total = 0; for (n in 0..<10) { total = total + n; } total;`)
+	require.Empty(b, diagnostics)
+
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		if _, err := Run(context.Background(), program); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
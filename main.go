@@ -2,10 +2,10 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"lox/interpreter"
-	"lox/lexer"
-	"lox/parser"
+	"lox/lox"
 	"os"
 	"strings"
 )
@@ -26,18 +26,16 @@ func fileMode(fileName string) {
 		fmt.Printf("Cant open file %v: %v\n", fileName, err)
 		return
 	}
-	stmts, err := parse(string(b))
-	if err != nil {
-		fmt.Println(err)
+	program, diagnostics := lox.Compile(fileName, string(b))
+	for _, d := range diagnostics {
+		fmt.Println(d)
+	}
+	if program == nil {
 		return
 	}
 
-	in := interpreter.NewInterpreter()
-	for _, s := range stmts {
-		if err := s.AcceptStatement(in); err != nil {
-			fmt.Println("got error:", err)
-			return
-		}
+	if _, err := lox.Run(context.Background(), program); err != nil {
+		fmt.Println("got error:", err)
 	}
 }
 
@@ -54,17 +52,12 @@ func interpreterMode() {
 			fmt.Println("Bye")
 			return
 		} else if line != "" {
-			
-			stmts, err := parse(line)
+			v, err := in.Eval(line)
 			if err != nil {
-				fmt.Println(err)
-				return
-			}
-
-			for _, s := range stmts {
-				s.AcceptStatement(in)
+				fmt.Println("got error:", err)
+			} else if !v.IsNil() {
+				fmt.Println(v)
 			}
-
 		}
 	}
 }
@@ -76,20 +69,3 @@ func getLine() string {
 	line = strings.TrimSuffix(line, "\n")
 	return line
 }
-
-func parse(input string) ([]parser.Statement, error) {
-	toks, err := lexer.Lex(input)
-	if err != nil {
-		return nil, fmt.Errorf("lexer error: %w", err)
-	}
-	got, errs := parser.NewParser(toks).Parse()
-	if len(errs) != 0 {
-		v := []string{}
-		for _, e := range errs {
-			v = append(v, e.Error())
-		}
-		return nil, fmt.Errorf("parser errors: %s", strings.Join(v, ","))
-	}
-	
-	return got, nil
-}
\ No newline at end of file
@@ -28,3 +28,12 @@ func (i *iter[T]) peek() (T, bool) {
 func (i *iter[T]) consume() {
 	i.idx++
 }
+
+
+func (i *iter[T]) position() int {
+	return i.idx
+}
+
+func (i *iter[T]) reset(position int) {
+	i.idx = position
+}
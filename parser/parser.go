@@ -58,21 +58,122 @@ func (p *Parser) parseStatement() (Statement, error) {
 		return p.parseAssignmentStatement()
 	} else if lexer.CheckToken(current, lexer.Opening, "{") {
 		return p.parseBlockStatement()
+	} else if lexer.CheckToken(current, lexer.Opening, "[") && p.isDestructuringAssignment() {
+		return p.parseDestructuringStatement()
 	} else if lexer.CheckToken(current, lexer.Keyword, "if") {
 		return p.parseIfStatement()
 	} else if lexer.CheckToken(current, lexer.Keyword, "while") {
 		return p.parseWhileStatement()
 	} else if lexer.CheckToken(current, lexer.Keyword, "function") {
 		return p.parseFunctionDeclaration()
+	} else if lexer.CheckToken(current, lexer.Keyword, "async") && nextOk && lexer.CheckToken(next, lexer.Keyword, "function") {
+		p.it.consume() // async
+		fn, err := p.parseFunctionDeclaration()
+		if err != nil {
+			return nil, fmt.Errorf("invalid async function declaration: %w", err)
+		}
+		return AsyncFunctionDeclaration{fn}, nil
+	} else if lexer.CheckToken(current, lexer.Keyword, "return") {
+		return p.parseReturnStatement()
+	} else if lexer.CheckToken(current, lexer.Keyword, "enum") {
+		return p.parseEnumDeclaration()
+	} else if lexer.CheckToken(current, lexer.Keyword, "class") {
+		return p.parseClassDeclaration()
+	} else if lexer.CheckToken(current, lexer.Keyword, "match") {
+		return p.parseMatchStatement()
+	} else if lexer.CheckToken(current, lexer.Keyword, "for") {
+		return p.parseForInStatement()
+	} else if lexer.CheckToken(current, lexer.Keyword, "yield") {
+		p.it.consume() // yield
+		v, err := p.parseTerminatedExpression()
+		if err != nil {
+			return nil, fmt.Errorf("yield statement syntax error: %w", err)
+		}
+		return YieldStatement{v}, nil
+	} else if lexer.CheckToken(current, lexer.Keyword, "spawn") {
+		p.it.consume() // spawn
+		v, err := p.parseTerminatedExpression()
+		if err != nil {
+			return nil, fmt.Errorf("spawn statement syntax error: %w", err)
+		}
+		if _, ok := v.(FunctionCall); ok {
+			return SpawnStatement{v}, nil
+		} else if _, ok := v.(Call); ok {
+			return SpawnStatement{v}, nil
+		}
+		return nil, makeError(current, "spawn statement expects a function call")
+	} else if lexer.CheckToken(current, lexer.Keyword, "select") {
+		return p.parseSelectStatement()
+	} else if lexer.CheckToken(current, lexer.Keyword, "break") {
+		p.it.consume() // break
+		if err := p.ensureCurrentTokenType(lexer.Semicolon); err != nil {
+			return nil, fmt.Errorf("break statement syntax error: %w", err)
+		}
+		p.it.consume() // ;
+		return BreakStatement{current.Line}, nil
+	} else if lexer.CheckToken(current, lexer.Keyword, "try") {
+		return p.parseTryStatement()
 	}
 
-	v, err := p.parseTerminatedExpression()
+	v, err := p.parseExpression()
 	if err != nil {
 		return nil, err
 	}
+
+	current, ok = p.it.current()
+	if index, isIndex := v.(Index); isIndex && ok && lexer.CheckToken(current, lexer.Operator, "=") {
+		p.it.consume() // =
+		value, err := p.parseTerminatedExpression()
+		if err != nil {
+			return nil, fmt.Errorf("index assignment syntax error: %w", err)
+		}
+		return IndexAssignmentStatement{Target: index, Expression: value}, nil
+	} else if get, isGet := v.(Get); isGet && ok && lexer.CheckToken(current, lexer.Operator, "=") {
+		p.it.consume() // =
+		value, err := p.parseTerminatedExpression()
+		if err != nil {
+			return nil, fmt.Errorf("property assignment syntax error: %w", err)
+		}
+		return PropertyAssignmentStatement{Target: get, Expression: value}, nil
+	} else if !ok || !lexer.CheckTokenType(current, lexer.Semicolon) {
+		return nil, makeError(current, "unterminated statement")
+	}
+	p.it.consume() // ;
 	return StatementExpression{v}, nil
 }
 
+func (p *Parser) parseTryStatement() (TryStatement, error) {
+	p.it.consume() // try
+
+	if err := p.ensureCurrentToken(lexer.Opening, "{"); err != nil {
+		return TryStatement{}, fmt.Errorf("try statement syntax error: %w", err)
+	}
+	body, err := p.parseBlockStatement()
+	if err != nil {
+		return TryStatement{}, fmt.Errorf("try statement syntax error (try block): %w", err)
+	}
+
+	if err := p.ensureCurrentToken(lexer.Keyword, "catch"); err != nil {
+		return TryStatement{}, fmt.Errorf("try statement syntax error: %w", err)
+	}
+	p.it.consume() // catch
+	names, err := p.parseIdentifierList()
+	if err != nil {
+		return TryStatement{}, fmt.Errorf("try statement syntax error: %w", err)
+	} else if len(names) != 1 {
+		return TryStatement{}, fmt.Errorf("try statement syntax error: catch expects single name, got %d", len(names))
+	}
+
+	if err := p.ensureCurrentToken(lexer.Opening, "{"); err != nil {
+		return TryStatement{}, fmt.Errorf("try statement syntax error: %w", err)
+	}
+	catch, err := p.parseBlockStatement()
+	if err != nil {
+		return TryStatement{}, fmt.Errorf("try statement syntax error (catch block): %w", err)
+	}
+	return TryStatement{Body: body, Name: names[0], Catch: catch}, nil
+}
+
 func (p *Parser) parseBlockStatement() (BlockStatement, error) {
 	p.it.consume() // {
 	statements := []Statement{}
@@ -121,6 +222,62 @@ func (p *Parser) parseWhileStatement() (WhileStatement, error) {
 	return WhileStatement{Predicate: pred, Body: block}, nil
 }
 
+func (p *Parser) parseReturnStatement() (ReturnStatement, error) {
+	current, _ := p.it.current()
+	p.it.consume() // return
+
+	if next, ok := p.it.current(); ok && lexer.CheckTokenType(next, lexer.Semicolon) {
+		p.it.consume() // ;
+		return ReturnStatement{Line: current.Line}, nil
+	}
+
+	v, err := p.parseTerminatedExpression()
+	if err != nil {
+		return ReturnStatement{}, fmt.Errorf("return statement syntax error: %w", err)
+	}
+	return ReturnStatement{Expression: v, Line: current.Line}, nil
+}
+
+func (p *Parser) parseForInStatement() (ForInStatement, error) {
+	p.it.consume() // for
+
+	if err := p.ensureCurrentToken(lexer.Opening, "("); err != nil {
+		return ForInStatement{}, fmt.Errorf("for statement syntax error: %w", err)
+	}
+	p.it.consume() // (
+
+	if err := p.ensureCurrentTokenType(lexer.Identifier); err != nil {
+		return ForInStatement{}, fmt.Errorf("for statement syntax error: %w", err)
+	}
+	current, _ := p.it.current()
+	name := current.Lexeme
+	p.it.consume() // identifier
+
+	if err := p.ensureCurrentToken(lexer.Keyword, "in"); err != nil {
+		return ForInStatement{}, fmt.Errorf("for statement syntax error: %w", err)
+	}
+	p.it.consume() // in
+
+	iterable, err := p.parseExpression()
+	if err != nil {
+		return ForInStatement{}, fmt.Errorf("for statement syntax error during parsing expression: %w", err)
+	}
+
+	if err := p.ensureCurrentToken(lexer.Closing, ")"); err != nil {
+		return ForInStatement{}, fmt.Errorf("for statement syntax error: %w", err)
+	}
+	p.it.consume() // )
+
+	if err := p.ensureCurrentToken(lexer.Opening, "{"); err != nil {
+		return ForInStatement{}, fmt.Errorf("for statement syntax error: %w", err)
+	}
+	block, err := p.parseBlockStatement()
+	if err != nil {
+		return ForInStatement{}, fmt.Errorf("for statement syntax error (block): %w", err)
+	}
+	return ForInStatement{Name: name, Iterable: iterable, Body: block}, nil
+}
+
 func (p *Parser) parseIfStatement() (IfStatement, error) {
 	parseSingleIf := func() (IfBlock, error) {
 		p.it.consume() // if
@@ -189,10 +346,36 @@ func (p *Parser) parseIfStatement() (IfStatement, error) {
 
 func (p *Parser) parseLetStatement() (Statement, error) {
 	p.it.consume() // let
-	if err := p.ensureCurrentTokenType(lexer.Identifier); err != nil {
+	if current, ok := p.it.current(); ok && (lexer.CheckToken(current, lexer.Opening, "[") || lexer.CheckToken(current, lexer.Opening, "{")) {
+		destructuring, err := p.parseDestructuringStatement()
+		if err != nil {
+			return nil, err
+		}
+		return LetDestructuringStatement{DestructuringStatement: destructuring}, nil
+	} else if err := p.ensureCurrentTokenType(lexer.Identifier); err != nil {
 		return nil, err
 	}
 
+	if next, ok := p.it.peek(); ok && lexer.CheckTokenType(next, lexer.Colon) {
+		current, _ := p.it.current()
+		p.it.consume() // identifier
+		typ, err := p.parseOptionalType()
+		if err != nil {
+			return nil, fmt.Errorf("invalid type annotation of %v: %w", current.Lexeme, err)
+		}
+
+		if err := p.ensureCurrentToken(lexer.Operator, "="); err != nil {
+			return nil, err
+		}
+		p.it.consume() // =
+
+		v, err := p.parseTerminatedExpression()
+		if err != nil {
+			return nil, err
+		}
+		return LetStatement{AssignmentStatement: AssignmentStatement{current.Lexeme, v}, Type: typ}, nil
+	}
+
 	assingnment, err := p.parseAssignmentStatement()
 	if err != nil {
 		return nil, err
@@ -217,6 +400,85 @@ func (p *Parser) parseAssignmentStatement() (AssignmentStatement, error) {
 	return AssignmentStatement{name, v}, nil
 }
 
+func (p *Parser) isDestructuringAssignment() bool {
+	start := p.it.position()
+	defer p.it.reset(start)
+
+	if _, err := p.parsePattern(); err != nil {
+		return false
+	}
+	return p.ensureCurrentToken(lexer.Operator, "=") == nil
+}
+
+func (p *Parser) parseDestructuringStatement() (DestructuringStatement, error) {
+	pattern, err := p.parsePattern()
+	if err != nil {
+		return DestructuringStatement{}, fmt.Errorf("invalid destructuring pattern: %w", err)
+	}
+
+	if err := p.ensureCurrentToken(lexer.Operator, "="); err != nil {
+		return DestructuringStatement{}, err
+	}
+	p.it.consume() // =
+
+	v, err := p.parseTerminatedExpression()
+	if err != nil {
+		return DestructuringStatement{}, err
+	}
+	return DestructuringStatement{Pattern: pattern, Expression: v}, nil
+}
+
+// parsePattern parses "[" names ( "..." IDENTIFIER )? "]" or "{" names "}"
+func (p *Parser) parsePattern() (Pattern, error) {
+	opening, _ := p.it.current()
+	out := Pattern{Names: []string{}, Object: opening.Lexeme == "{"}
+	closing := "]"
+	if out.Object {
+		closing = "}"
+	}
+	p.it.consume() // [ or {
+
+	for {
+		current, ok := p.it.current()
+		if !ok {
+			return Pattern{}, eofError()
+		} else if lexer.CheckToken(current, lexer.Closing, closing) {
+			p.it.consume()
+			return out, nil
+		} else if !out.Object && lexer.CheckToken(current, lexer.Operator, "...") {
+			p.it.consume() // ...
+			if err := p.ensureCurrentTokenType(lexer.Identifier); err != nil {
+				return Pattern{}, fmt.Errorf("expected rest variable name: %w", err)
+			}
+			rest, _ := p.it.current()
+			out.Rest = rest.Lexeme
+			p.it.consume() // identifier
+
+			if err := p.ensureCurrentToken(lexer.Closing, closing); err != nil {
+				return Pattern{}, fmt.Errorf("rest variable has to be the last one: %w", err)
+			}
+			p.it.consume()
+			return out, nil
+		} else if err := p.ensureCurrentTokenType(lexer.Identifier); err != nil {
+			return Pattern{}, makeError(current, fmt.Sprintf("expected identifiers or '%v'", closing))
+		}
+
+		out.Names = append(out.Names, current.Lexeme)
+		p.it.consume() // identifier
+
+		current, ok = p.it.current()
+		if !ok {
+			return Pattern{}, eofError()
+		} else if lexer.CheckToken(current, lexer.Closing, closing) {
+			p.it.consume()
+			return out, nil
+		} else if err := p.ensureCurrentTokenType(lexer.Comma); err != nil {
+			return Pattern{}, fmt.Errorf("identifiers should be comma separated: %w", err)
+		}
+		p.it.consume() // ,
+	}
+}
+
 func (p *Parser) parseFunctionDeclaration() (FunctionDeclaration, error) {
 	p.it.consume() // function
 	
@@ -231,47 +493,379 @@ func (p *Parser) parseFunctionDeclaration() (FunctionDeclaration, error) {
 		return FunctionDeclaration{}, fmt.Errorf("invalid function declaration: %w", err)
 	}
 
+	args, argTypes, err := p.parseParameters()
+	if err != nil {
+		return FunctionDeclaration{}, fmt.Errorf("invalid function declaration: %w", err)
+	}
+
+	returnType, err := p.parseOptionalType()
+	if err != nil {
+		return FunctionDeclaration{}, fmt.Errorf("invalid function return type: %w", err)
+	}
+
+	if err := p.ensureCurrentToken(lexer.Opening, "{"); err != nil {
+		return FunctionDeclaration{}, fmt.Errorf("invalid function declaration: %w", err)
+	}
+	block, err := p.parseBlockStatement()
+	if err != nil {
+		return FunctionDeclaration{}, fmt.Errorf("invalid function declaration: %w", err)
+	}
+
+	var signature *Signature
+	for _, typ := range argTypes {
+		if typ != nil {
+			signature = &Signature{Args: argTypes, Return: returnType}
+		}
+	}
+	if returnType != nil {
+		signature = &Signature{Args: argTypes, Return: returnType}
+	}
+
+	return FunctionDeclaration{
+		Name:      name,
+		Args:      args,
+		Body:      block,
+		Signature: signature,
+	}, nil
+}
+
+// parseIdentifierList parses "(" ( IDENTIFIER ( "," IDENTIFIER )* )? ")"
+func (p *Parser) parseIdentifierList() ([]string, error) {
+	out := []string{}
+	err := p.parseParenthesized(func() error {
+		current, _ := p.it.current()
+		if err := p.ensureCurrentTokenType(lexer.Identifier); err != nil {
+			return makeError(current, "expected identifiers or ')'")
+		}
+		out = append(out, current.Lexeme)
+		p.it.consume() // identifier
+		return nil
+	})
+	return out, err
+}
+
+// parseParameters parses "(" ( IDENTIFIER ( ":" type )? ( "," ... )* )? ")"
+func (p *Parser) parseParameters() ([]string, []*TypeAnnotation, error) {
+	names := []string{}
+	types := []*TypeAnnotation{}
+	err := p.parseParenthesized(func() error {
+		current, _ := p.it.current()
+		if err := p.ensureCurrentTokenType(lexer.Identifier); err != nil {
+			return makeError(current, "expected identifiers or ')'")
+		}
+		names = append(names, current.Lexeme)
+		p.it.consume() // identifier
+
+		typ, err := p.parseOptionalType()
+		if err != nil {
+			return err
+		}
+		types = append(types, typ)
+		return nil
+	})
+	return names, types, err
+}
+
+// parseParenthesized parses "(" ( item ( "," item )* )? ")"
+func (p *Parser) parseParenthesized(item func() error) error {
 	p.it.consume() // (
-	args := []string{}
 	for {
 		current, ok := p.it.current()
 		if !ok {
-			return FunctionDeclaration{}, eofError()
+			return eofError()
 		} else if lexer.CheckToken(current, lexer.Closing, ")") {
 			p.it.consume()
-			break
-		} else if err := p.ensureCurrentTokenType(lexer.Identifier); err != nil {
-			return FunctionDeclaration{}, makeError(current, "invalid function declaration, expected identifiers or ')'")
+			return nil
+		} else if err := item(); err != nil {
+			return err
 		}
 
-		args = append(args, current.Lexeme)
-		p.it.consume() // identifier
-	
 		current, ok = p.it.current()
 		if !ok {
-			return FunctionDeclaration{}, eofError()
+			return eofError()
 		} else if lexer.CheckToken(current, lexer.Closing, ")") {
+			p.it.consume()
+			return nil
+		} else if err := p.ensureCurrentTokenType(lexer.Comma); err != nil {
+			return fmt.Errorf("identifiers should be comma separated: %w", err)
+		}
+		p.it.consume() // ,
+	}
+}
+
+// parseOptionalType parses ( ":" ( IDENTIFIER | "nil" ) )?
+func (p *Parser) parseOptionalType() (*TypeAnnotation, error) {
+	current, ok := p.it.current()
+	if !ok || !lexer.CheckTokenType(current, lexer.Colon) {
+		return nil, nil
+	}
+	p.it.consume() // :
+
+	current, ok = p.it.current()
+	if !ok {
+		return nil, eofError()
+	} else if !lexer.CheckTokenType(current, lexer.Identifier) && !lexer.CheckTokenType(current, lexer.Nil) {
+		return nil, makeError(current, "expected type name")
+	}
+	p.it.consume() // type
+	return &TypeAnnotation{Name: current.Lexeme, Line: current.Line}, nil
+}
+
+func (p *Parser) parseEnumDeclaration() (EnumDeclaration, error) {
+	p.it.consume() // enum
+
+	if err := p.ensureCurrentTokenType(lexer.Identifier); err != nil {
+		return EnumDeclaration{}, fmt.Errorf("invalid enum declaration: %w", err)
+	}
+	current, _ := p.it.current()
+	name := current.Lexeme
+	p.it.consume() // identifier
+
+	if err := p.ensureCurrentToken(lexer.Opening, "{"); err != nil {
+		return EnumDeclaration{}, fmt.Errorf("invalid enum declaration: %w", err)
+	}
+	p.it.consume() // {
+
+	variants := []EnumVariant{}
+	for {
+		current, ok := p.it.current()
+		if !ok {
+			return EnumDeclaration{}, eofError()
+		} else if lexer.CheckToken(current, lexer.Closing, "}") && len(variants) > 0 {
 			p.it.consume()
 			break
+		} else if err := p.ensureCurrentTokenType(lexer.Identifier); err != nil {
+			return EnumDeclaration{}, makeError(current, "invalid enum declaration, expected variant name")
+		}
+		variant := EnumVariant{Name: current.Lexeme, Fields: []string{}}
+		p.it.consume() // identifier
+
+		current, ok = p.it.current()
+		if ok && lexer.CheckToken(current, lexer.Opening, "(") {
+			fields, err := p.parseIdentifierList()
+			if err != nil {
+				return EnumDeclaration{}, fmt.Errorf("invalid enum variant %v: %w", variant.Name, err)
+			}
+			variant.Fields = fields
+		}
+		variants = append(variants, variant)
+
+		current, ok = p.it.current()
+		if !ok {
+			return EnumDeclaration{}, eofError()
+		} else if lexer.CheckToken(current, lexer.Closing, "}") || lexer.CheckToken(current, lexer.Keyword, "function") {
+			break
 		} else if err := p.ensureCurrentTokenType(lexer.Comma); err != nil {
-			return FunctionDeclaration{}, fmt.Errorf("function arguments should be comma separated: %w", err)
+			return EnumDeclaration{}, fmt.Errorf("enum variants should be comma separated: %w", err)
 		}
 		p.it.consume() // ,
 	}
 
+	var methods []FunctionDeclaration
+	for {
+		current, ok := p.it.current()
+		if !ok {
+			return EnumDeclaration{}, eofError()
+		} else if lexer.CheckToken(current, lexer.Closing, "}") {
+			p.it.consume()
+			break
+		} else if !lexer.CheckToken(current, lexer.Keyword, "function") {
+			return EnumDeclaration{}, makeError(current, "invalid enum declaration, expected method")
+		}
+
+		method, err := p.parseFunctionDeclaration()
+		if err != nil {
+			return EnumDeclaration{}, fmt.Errorf("invalid method of enum %v: %w", name, err)
+		}
+		methods = append(methods, method)
+	}
+
+	return EnumDeclaration{Name: name, Variants: variants, Methods: methods}, nil
+}
+
+func (p *Parser) parseClassDeclaration() (ClassDeclaration, error) {
+	p.it.consume() // class
+
+	if err := p.ensureCurrentTokenType(lexer.Identifier); err != nil {
+		return ClassDeclaration{}, fmt.Errorf("invalid class declaration: %w", err)
+	}
+	current, _ := p.it.current()
+	name := current.Lexeme
+	p.it.consume() // identifier
+
 	if err := p.ensureCurrentToken(lexer.Opening, "{"); err != nil {
-		return FunctionDeclaration{}, fmt.Errorf("invalid function declaration: %w", err)
+		return ClassDeclaration{}, fmt.Errorf("invalid class declaration: %w", err)
 	}
-	block, err := p.parseBlockStatement()
+	p.it.consume() // {
+
+	methods := []FunctionDeclaration{}
+	for {
+		current, ok := p.it.current()
+		if !ok {
+			return ClassDeclaration{}, eofError()
+		} else if lexer.CheckToken(current, lexer.Closing, "}") {
+			p.it.consume()
+			break
+		} else if !lexer.CheckToken(current, lexer.Keyword, "function") {
+			return ClassDeclaration{}, makeError(current, "invalid class declaration, expected method")
+		}
+
+		method, err := p.parseFunctionDeclaration()
+		if err != nil {
+			return ClassDeclaration{}, fmt.Errorf("invalid method of class %v: %w", name, err)
+		}
+		methods = append(methods, method)
+	}
+
+	return ClassDeclaration{Name: name, Methods: methods}, nil
+}
+
+func (p *Parser) parseMatchStatement() (MatchStatement, error) {
+	p.it.consume() // match
+
+	if err := p.ensureCurrentToken(lexer.Opening, "("); err != nil {
+		return MatchStatement{}, fmt.Errorf("match statement syntax error: %w", err)
+	}
+	p.it.consume() // (
+
+	subject, err := p.parseExpression()
 	if err != nil {
-		return FunctionDeclaration{}, fmt.Errorf("invalid function declaration: %w", err)
+		return MatchStatement{}, fmt.Errorf("match statement syntax error during parsing expression: %w", err)
 	}
 
-	return FunctionDeclaration{
-		Name: name,
-		Args: args,
-		Body: block,
-	}, nil
+	if err := p.ensureCurrentToken(lexer.Closing, ")"); err != nil {
+		return MatchStatement{}, fmt.Errorf("match statement syntax error: %w", err)
+	}
+	p.it.consume() // )
+
+	if err := p.ensureCurrentToken(lexer.Opening, "{"); err != nil {
+		return MatchStatement{}, fmt.Errorf("match statement syntax error: %w", err)
+	}
+	p.it.consume() // {
+
+	out := MatchStatement{Subject: subject}
+	for {
+		current, ok := p.it.current()
+		if !ok {
+			return MatchStatement{}, eofError()
+		} else if lexer.CheckToken(current, lexer.Closing, "}") {
+			p.it.consume()
+			return out, nil
+		} else if out.Else != nil {
+			return MatchStatement{}, makeError(current, "else should be the last branch of match statement")
+		} else if lexer.CheckToken(current, lexer.Keyword, "else") {
+			p.it.consume() // else
+			if err := p.ensureCurrentToken(lexer.Opening, "{"); err != nil {
+				return MatchStatement{}, fmt.Errorf("match statement syntax error: %w", err)
+			}
+			block, err := p.parseBlockStatement()
+			if err != nil {
+				return MatchStatement{}, fmt.Errorf("match statement syntax error (else block): %w", err)
+			}
+			out.Else = &block
+			continue
+		} else if !lexer.CheckToken(current, lexer.Keyword, "case") {
+			return MatchStatement{}, makeError(current, "expected case or else in match statement")
+		}
+		p.it.consume() // case
+
+		if err := p.ensureCurrentTokenType(lexer.Identifier); err != nil {
+			return MatchStatement{}, fmt.Errorf("match statement syntax error: %w", err)
+		}
+		current, _ = p.it.current()
+		matchCase := MatchCase{Variant: current.Lexeme, Bindings: []string{}, Line: current.Line}
+		p.it.consume() // identifier
+
+		current, ok = p.it.current()
+		if ok && lexer.CheckToken(current, lexer.Opening, "(") {
+			bindings, err := p.parseIdentifierList()
+			if err != nil {
+				return MatchStatement{}, fmt.Errorf("match statement syntax error (case %v): %w", matchCase.Variant, err)
+			}
+			matchCase.Bindings = bindings
+		}
+
+		if err := p.ensureCurrentToken(lexer.Opening, "{"); err != nil {
+			return MatchStatement{}, fmt.Errorf("match statement syntax error: %w", err)
+		}
+		block, err := p.parseBlockStatement()
+		if err != nil {
+			return MatchStatement{}, fmt.Errorf("match statement syntax error (case %v): %w", matchCase.Variant, err)
+		}
+		matchCase.Body = block
+		out.Cases = append(out.Cases, matchCase)
+	}
+}
+
+func (p *Parser) parseSelectStatement() (SelectStatement, error) {
+	p.it.consume() // select
+
+	if err := p.ensureCurrentToken(lexer.Opening, "{"); err != nil {
+		return SelectStatement{}, fmt.Errorf("select statement syntax error: %w", err)
+	}
+	p.it.consume() // {
+
+	out := SelectStatement{}
+	for {
+		current, ok := p.it.current()
+		if !ok {
+			return SelectStatement{}, eofError()
+		} else if lexer.CheckToken(current, lexer.Closing, "}") {
+			p.it.consume()
+			return out, nil
+		} else if out.Else != nil {
+			return SelectStatement{}, makeError(current, "else should be the last branch of select statement")
+		} else if lexer.CheckToken(current, lexer.Keyword, "else") {
+			p.it.consume() // else
+			if err := p.ensureCurrentToken(lexer.Opening, "{"); err != nil {
+				return SelectStatement{}, fmt.Errorf("select statement syntax error: %w", err)
+			}
+			block, err := p.parseBlockStatement()
+			if err != nil {
+				return SelectStatement{}, fmt.Errorf("select statement syntax error (else block): %w", err)
+			}
+			out.Else = &block
+			continue
+		} else if !lexer.CheckToken(current, lexer.Keyword, "case") {
+			return SelectStatement{}, makeError(current, "expected case or else in select statement")
+		}
+		p.it.consume() // case
+
+		selectCase := SelectCase{}
+		current, ok = p.it.current()
+		next, nextOk := p.it.peek()
+		if ok && lexer.CheckTokenType(current, lexer.Identifier) && nextOk && lexer.CheckToken(next, lexer.Operator, "=") {
+			selectCase.Name = current.Lexeme
+			p.it.consume() // identifier
+			p.it.consume() // =
+		}
+
+		current, _ = p.it.current()
+		ex, err := p.parseExpression()
+		if err != nil {
+			return SelectStatement{}, fmt.Errorf("select statement syntax error: %w", err)
+		}
+		call, ok := ex.(FunctionCall)
+		if ok && call.Name == "recv" && len(call.Args) == 1 {
+			selectCase.Channel = call.Args[0]
+		} else if ok && call.Name == "send" && len(call.Args) == 2 && selectCase.Name == "" {
+			selectCase.Send = true
+			selectCase.Channel = call.Args[0]
+			selectCase.Value = call.Args[1]
+		} else {
+			return SelectStatement{}, makeError(current, "select case expects recv(channel) or send(channel, value)")
+		}
+
+		if err := p.ensureCurrentToken(lexer.Opening, "{"); err != nil {
+			return SelectStatement{}, fmt.Errorf("select statement syntax error: %w", err)
+		}
+		block, err := p.parseBlockStatement()
+		if err != nil {
+			return SelectStatement{}, fmt.Errorf("select statement syntax error (case block): %w", err)
+		}
+		selectCase.Body = block
+		out.Cases = append(out.Cases, selectCase)
+	}
 }
 
 func (p *Parser) parseExpression() (Expression, error) {
@@ -283,7 +877,25 @@ func (p *Parser) parseEquality() (Expression, error) {
 }
 
 func (p *Parser) parseComparison() (Expression, error) {
-	return p.parseBinaryHelper(p.parseTerm, []string{">", ">=", "<", "<="})
+	return p.parseBinaryHelper(p.parseRange, []string{">", ">=", "<", "<=", "in"})
+}
+
+func (p *Parser) parseRange() (Expression, error) {
+	ex, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+
+	current, ok := p.it.current()
+	if ok && (lexer.CheckToken(current, lexer.Operator, "..") || lexer.CheckToken(current, lexer.Operator, "..<")) {
+		p.it.consume()
+		end, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		return Binary{Op: current, Left: ex, Right: end}, nil
+	}
+	return ex, nil
 }
 
 func (p *Parser) parseTerm() (Expression, error) {
@@ -302,7 +914,7 @@ func (p *Parser) parseBinaryHelper(parseExpressionFn func()(Expression,error), a
 
 	currentTokenInSet := func (current lexer.Token) bool {
 		for _, v := range allowedOperators {
-			if lexer.CheckToken(current, lexer.Operator, v) {
+			if lexer.CheckToken(current, lexer.Operator, v) || lexer.CheckToken(current, lexer.Keyword, v) {
 				return true
 			}
 		}
@@ -335,39 +947,71 @@ func (p *Parser) parseUnary() (Expression, error) {
 			return nil, err
 		}
 		return Unary{Op: op, Ex: e}, nil
+	} else if ok && lexer.CheckToken(current, lexer.Keyword, "await") {
+		p.it.consume()
+		e, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return Await{Keyword: current, Ex: e}, nil
 	}
 	return p.parseCall()
 }
 
 func (p *Parser) parseCall() (Expression, error) {
-	next, nextOk := p.it.peek()
-	current, ok := p.it.current()
-	functionName := ""
-	if ok && lexer.CheckTokenType(current, lexer.Identifier) && nextOk && lexer.CheckToken(next, lexer.Opening, "(") {
-		functionName = current.Lexeme
-	}
-
 	ex, err := p.parsePrimary()
 	if err != nil {
 		return nil, err
 	}
 
-	current, ok = p.it.current()
-	if !ok || !lexer.CheckToken(current, lexer.Opening, "(") {
-		return ex, nil
-	}
+	for {
+		current, ok := p.it.current()
+		if ok && lexer.CheckToken(current, lexer.Opening, "(") {
+			args, err := p.parseExpressionList(")")
+			if err != nil {
+				return nil, err
+			}
 
-	if functionName == "" {
-		return nil, makeError(next, "empty function name found")
+			if lit, isLiteral := ex.(Literal); isLiteral && lexer.CheckTokenType(lexer.Token(lit), lexer.Identifier) {
+				ex = FunctionCall{lit.Lexeme, args}
+			} else {
+				ex = Call{Callee: ex, Args: args}
+			}
+		} else if ok && lexer.CheckTokenType(current, lexer.Dot) {
+			p.it.consume() // .
+			if err := p.ensureCurrentTokenType(lexer.Identifier); err != nil {
+				return nil, fmt.Errorf("property access syntax error: %w", err)
+			}
+			name, _ := p.it.current()
+			p.it.consume() // identifier
+			ex = Get{Object: ex, Name: name}
+		} else if ok && lexer.CheckToken(current, lexer.Opening, "[") {
+			p.it.consume() // [
+			index, err := p.parseExpression()
+			if err != nil {
+				return nil, fmt.Errorf("index expression parsing error: %w", err)
+			}
+			if err := p.ensureCurrentToken(lexer.Closing, "]"); err != nil {
+				return nil, fmt.Errorf("index expression parsing error: %w", err)
+			}
+			p.it.consume() // ]
+			ex = Index{Object: ex, Bracket: current, Index: index}
+		} else {
+			return ex, nil
+		}
 	}
-	
-	p.it.consume() // (
-	current, ok = p.it.current()
+}
+
+// parseExpressionList parses comma separated expressions between
+// the current opening token and the given closing one
+func (p *Parser) parseExpressionList(closing string) ([]Expression, error) {
+	p.it.consume() // ( or [
+	current, ok := p.it.current()
 	if !ok {
-		return nil, eofError()			
-	} else if lexer.CheckToken(current, lexer.Closing, ")") {
+		return nil, eofError()
+	} else if lexer.CheckToken(current, lexer.Closing, closing) {
 		p.it.consume()
-		return FunctionCall{functionName, []Expression{}}, nil
+		return []Expression{}, nil
 	}
 
 	args := []Expression{}
@@ -380,10 +1024,10 @@ func (p *Parser) parseCall() (Expression, error) {
 
 		current, ok = p.it.current()
 		if !ok {
-			return nil, eofError()			
-		} else if lexer.CheckToken(current, lexer.Closing, ")") {
-			p.it.consume() // )
-			return FunctionCall{functionName, args}, nil
+			return nil, eofError()
+		} else if lexer.CheckToken(current, lexer.Closing, closing) {
+			p.it.consume() // ) or ]
+			return args, nil
 		} else if err := p.ensureCurrentTokenType(lexer.Comma); err != nil {
 			return nil, fmt.Errorf("argument expressions parsing error: %w", err)
 		}
@@ -407,19 +1051,82 @@ func (p *Parser) parsePrimary() (Expression, error) {
 		}
 		p.it.consume()
 		return ex, nil
-	} else if lexer.CheckTokenType(current, lexer.Number) || lexer.CheckTokenType(current, lexer.Boolean) || lexer.CheckTokenType(current, lexer.StringLiteral) || lexer.CheckTokenType(current, lexer.Identifier) {
+	} else if lexer.CheckToken(current, lexer.Opening, "[") {
+		elements, err := p.parseExpressionList("]")
+		if err != nil {
+			return nil, fmt.Errorf("list literal parsing error: %w", err)
+		}
+		return ListLiteral{Elements: elements}, nil
+	} else if lexer.CheckToken(current, lexer.Opening, "{") {
+		return p.parseMapLiteral()
+	} else if lexer.CheckTokenType(current, lexer.Number) || lexer.CheckTokenType(current, lexer.Boolean) || lexer.CheckTokenType(current, lexer.StringLiteral) || lexer.CheckTokenType(current, lexer.Identifier) || lexer.CheckTokenType(current, lexer.Nil) {
 		p.it.consume()
 		return Literal(current), nil
 	}
 	return nil, makeError(current, "unexpected token when parsing primary expression")
 }
 
+// parseMapLiteral parses "{" ( expression ":" expression ( "," expression ":" expression )* )? "}"
+func (p *Parser) parseMapLiteral() (Expression, error) {
+	p.it.consume() // {
+	out := MapLiteral{Keys: []Expression{}, Values: []Expression{}}
+	for {
+		current, ok := p.it.current()
+		if !ok {
+			return nil, eofError()
+		} else if lexer.CheckToken(current, lexer.Closing, "}") && len(out.Keys) == 0 {
+			p.it.consume()
+			return out, nil
+		}
+
+		key, err := p.parseExpression()
+		if err != nil {
+			return nil, fmt.Errorf("map literal parsing error: %w", err)
+		}
+		if err := p.ensureCurrentTokenType(lexer.Colon); err != nil {
+			return nil, fmt.Errorf("map literal parsing error: %w", err)
+		}
+		p.it.consume() // :
+		value, err := p.parseExpression()
+		if err != nil {
+			return nil, fmt.Errorf("map literal parsing error: %w", err)
+		}
+		out.Keys = append(out.Keys, key)
+		out.Values = append(out.Values, value)
+
+		current, ok = p.it.current()
+		if !ok {
+			return nil, eofError()
+		} else if lexer.CheckToken(current, lexer.Closing, "}") {
+			p.it.consume()
+			return out, nil
+		} else if err := p.ensureCurrentTokenType(lexer.Comma); err != nil {
+			return nil, fmt.Errorf("map literal parsing error: %w", err)
+		}
+		p.it.consume() // ,
+	}
+}
+
+// Error is a syntax error at the token, errors at the end of tokens have zero Line
+type Error struct {
+	Line    int
+	Token   *lexer.Token
+	Message string
+}
+
+func (e Error) Error() string {
+	if e.Token == nil {
+		return e.Message
+	}
+	return fmt.Sprintf("%v, at line %v at token %v", e.Message, e.Line, *e.Token)
+}
+
 func makeError(tok lexer.Token, msg string) error {
-	return fmt.Errorf("%v, at line %v at token %v", msg, tok.Line, tok)
+	return Error{Line: tok.Line, Token: &tok, Message: msg}
 }
 
 func eofError() error {
-	return fmt.Errorf("unexpected end of tokens")
+	return Error{Message: "unexpected end of tokens"}
 }
 
 func (p *Parser) recover() {
@@ -429,7 +1136,8 @@ func (p *Parser) recover() {
 			break
 		} else if lexer.CheckToken(current, lexer.Keyword, "let") || 
 			lexer.CheckToken(current, lexer.Keyword, "function") ||
-			lexer.CheckToken(current, lexer.Keyword, "while") {
+			lexer.CheckToken(current, lexer.Keyword, "while") ||
+			lexer.CheckToken(current, lexer.Keyword, "for") {
 			break
 		}
 
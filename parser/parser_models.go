@@ -11,6 +11,12 @@ type VisitorExpr interface {
 	VisitUnary(Unary) (any, error)
 	VisitBinary(Binary) (any, error)
 	VisitFunctionCall(FunctionCall) (any, error)
+	VisitCall(Call) (any, error)
+	VisitGet(Get) (any, error)
+	VisitListLiteral(ListLiteral) (any, error)
+	VisitAwait(Await) (any, error)
+	VisitIndex(Index) (any, error)
+	VisitMapLiteral(MapLiteral) (any, error)
 }
 
 type Literal lexer.Token
@@ -50,7 +56,21 @@ type VisitorStatement interface {
 	VisitIfStatement(IfStatement) error
 	VisitWhileStatement(WhileStatement) error
 	VisitFunctionDeclarationStatement(FunctionDeclaration) error
-	VisitNativeCallStatement(NativeCallStatement) error
+	VisitEnumDeclarationStatement(EnumDeclaration) error
+	VisitClassDeclarationStatement(ClassDeclaration) error
+	VisitMatchStatement(MatchStatement) error
+	VisitDestructuringStatement(DestructuringStatement) error
+	VisitLetDestructuringStatement(LetDestructuringStatement) error
+	VisitForInStatement(ForInStatement) error
+	VisitYieldStatement(YieldStatement) error
+	VisitBreakStatement(BreakStatement) error
+	VisitSpawnStatement(SpawnStatement) error
+	VisitSelectStatement(SelectStatement) error
+	VisitReturnStatement(ReturnStatement) error
+	VisitAsyncFunctionDeclarationStatement(AsyncFunctionDeclaration) error
+	VisitIndexAssignmentStatement(IndexAssignmentStatement) error
+	VisitPropertyAssignmentStatement(PropertyAssignmentStatement) error
+	VisitTryStatement(TryStatement) error
 }
 
 type StatementExpression struct {
@@ -63,6 +83,13 @@ func (s StatementExpression) AcceptStatement(v VisitorStatement) error {
 
 type LetStatement struct {
 	AssignmentStatement
+	Type *TypeAnnotation
+}
+
+// TypeAnnotation is an optional type of variable, parameter or returned value, e.g. `let x: number = 1;`
+type TypeAnnotation struct {
+	Name string
+	Line int
 }
 
 func (s LetStatement) AcceptStatement(v VisitorStatement) error {
@@ -110,22 +137,21 @@ func (w WhileStatement) AcceptStatement(v VisitorStatement) error {
 }
 
 type FunctionDeclaration struct {
-	Name string
-	Args []string
-	Body BlockStatement
-}
-
-func (f FunctionDeclaration) AcceptStatement(v VisitorStatement) error {
-	return v.VisitFunctionDeclarationStatement(f)
+	Name      string
+	Args      []string
+	Body      BlockStatement
+	Signature *Signature
 }
 
-type NativeCallStatement struct {
-	Fn func([]any) error
-	Args []string
+// Signature is set only when function has at least one type annotation.
+// Unannotated arguments have nil type
+type Signature struct {
+	Args   []*TypeAnnotation
+	Return *TypeAnnotation
 }
 
-func (n NativeCallStatement) AcceptStatement(v VisitorStatement) error {
-	return v.VisitNativeCallStatement(n)
+func (f FunctionDeclaration) AcceptStatement(v VisitorStatement) error {
+	return v.VisitFunctionDeclarationStatement(f)
 }
 
 type FunctionCall struct {
@@ -135,4 +161,282 @@ type FunctionCall struct {
 
 func (f FunctionCall) AcceptExpr(v VisitorExpr) (any, error) {
 	return v.VisitFunctionCall(f)
-}
\ No newline at end of file
+}
+
+type Call struct {
+	Callee Expression
+	Args   []Expression
+}
+
+func (c Call) AcceptExpr(v VisitorExpr) (any, error) {
+	return v.VisitCall(c)
+}
+
+type Get struct {
+	Object Expression
+	Name   lexer.Token
+}
+
+func (g Get) AcceptExpr(v VisitorExpr) (any, error) {
+	return v.VisitGet(g)
+}
+
+type Index struct {
+	Object  Expression
+	Bracket lexer.Token
+	Index   Expression
+}
+
+func (i Index) AcceptExpr(v VisitorExpr) (any, error) {
+	return v.VisitIndex(i)
+}
+
+// EnumDeclaration may declare methods after its variants. Methods take the
+// enum value as the first parameter, methods named like __add__ overload operators
+type EnumDeclaration struct {
+	Name     string
+	Variants []EnumVariant
+	Methods  []FunctionDeclaration
+}
+
+type EnumVariant struct {
+	Name   string
+	Fields []string
+}
+
+func (e EnumDeclaration) AcceptStatement(v VisitorStatement) error {
+	return v.VisitEnumDeclarationStatement(e)
+}
+
+// ClassDeclaration declares methods taking the instance as the first parameter, like methods
+// of enums. Calling the class creates an instance and passes the arguments to its init method
+type ClassDeclaration struct {
+	Name    string
+	Methods []FunctionDeclaration
+}
+
+func (c ClassDeclaration) AcceptStatement(v VisitorStatement) error {
+	return v.VisitClassDeclarationStatement(c)
+}
+
+type MatchStatement struct {
+	Subject Expression
+	Cases   []MatchCase
+	Else    *BlockStatement
+}
+
+type MatchCase struct {
+	Variant  string
+	Bindings []string
+	Body     BlockStatement
+	Line     int
+}
+
+func (m MatchStatement) AcceptStatement(v VisitorStatement) error {
+	return v.VisitMatchStatement(m)
+}
+
+type ListLiteral struct {
+	Elements []Expression
+}
+
+func (l ListLiteral) AcceptExpr(v VisitorExpr) (any, error) {
+	return v.VisitListLiteral(l)
+}
+
+// MapLiteral keeps entries in the source order, which is the iteration order of the map
+type MapLiteral struct {
+	Keys   []Expression
+	Values []Expression
+}
+
+func (m MapLiteral) AcceptExpr(v VisitorExpr) (any, error) {
+	return v.VisitMapLiteral(m)
+}
+
+// IndexAssignmentStatement sets an element of a list or a map, xs[i] = v;
+type IndexAssignmentStatement struct {
+	Target     Index
+	Expression Expression
+}
+
+func (a IndexAssignmentStatement) AcceptStatement(v VisitorStatement) error {
+	return v.VisitIndexAssignmentStatement(a)
+}
+
+// TryStatement runs Catch block with the error bound to Name when Body fails
+type TryStatement struct {
+	Body  BlockStatement
+	Name  string
+	Catch BlockStatement
+}
+
+func (t TryStatement) AcceptStatement(v VisitorStatement) error {
+	return v.VisitTryStatement(t)
+}
+
+// Pattern is a left side of destructuring, either a list pattern
+// `[a, b, ...rest]` or an object pattern `{name, age}`
+type Pattern struct {
+	Names  []string
+	Rest   string
+	Object bool
+}
+
+type DestructuringStatement struct {
+	Pattern Pattern
+	Expression
+}
+
+func (d DestructuringStatement) AcceptStatement(v VisitorStatement) error {
+	return v.VisitDestructuringStatement(d)
+}
+
+type LetDestructuringStatement struct {
+	DestructuringStatement
+}
+
+func (l LetDestructuringStatement) AcceptStatement(v VisitorStatement) error {
+	return v.VisitLetDestructuringStatement(l)
+}
+
+type ForInStatement struct {
+	Name     string
+	Iterable Expression
+	Body     BlockStatement
+}
+
+func (f ForInStatement) AcceptStatement(v VisitorStatement) error {
+	return v.VisitForInStatement(f)
+}
+
+type YieldStatement struct {
+	Expression
+}
+
+func (y YieldStatement) AcceptStatement(v VisitorStatement) error {
+	return v.VisitYieldStatement(y)
+}
+
+type BreakStatement struct {
+	Line int
+}
+
+func (b BreakStatement) AcceptStatement(v VisitorStatement) error {
+	return v.VisitBreakStatement(b)
+}
+
+// SpawnStatement holds either FunctionCall or Call expression to be run on its own goroutine
+type SpawnStatement struct {
+	Call Expression
+}
+
+func (s SpawnStatement) AcceptStatement(v VisitorStatement) error {
+	return v.VisitSpawnStatement(s)
+}
+
+type SelectStatement struct {
+	Cases []SelectCase
+	Else  *BlockStatement
+}
+
+// SelectCase is either `case recv(ch)`, `case v = recv(ch)` or `case send(ch, v)`
+type SelectCase struct {
+	Name    string
+	Send    bool
+	Channel Expression
+	Value   Expression
+	Body    BlockStatement
+}
+
+func (s SelectStatement) AcceptStatement(v VisitorStatement) error {
+	return v.VisitSelectStatement(s)
+}
+
+// ReturnStatement without a value has nil Expression
+type ReturnStatement struct {
+	Expression
+	Line int
+}
+
+func (r ReturnStatement) AcceptStatement(v VisitorStatement) error {
+	return v.VisitReturnStatement(r)
+}
+
+type AsyncFunctionDeclaration struct {
+	FunctionDeclaration
+}
+
+func (a AsyncFunctionDeclaration) AcceptStatement(v VisitorStatement) error {
+	return v.VisitAsyncFunctionDeclarationStatement(a)
+}
+
+// PropertyAssignmentStatement sets a property of an object, obj.name = v;
+type PropertyAssignmentStatement struct {
+	Target     Get
+	Expression Expression
+}
+
+func (a PropertyAssignmentStatement) AcceptStatement(v VisitorStatement) error {
+	return v.VisitPropertyAssignmentStatement(a)
+}
+
+type Await struct {
+	Keyword lexer.Token
+	Ex      Expression
+}
+
+func (a Await) AcceptExpr(v VisitorExpr) (any, error) {
+	return v.VisitAwait(a)
+}
+
+// ContainsYield reports whether a function body with these statements is a generator
+func ContainsYield(stmts []Statement) bool {
+	for _, s := range stmts {
+		switch stmt := s.(type) {
+		case YieldStatement:
+			return true
+		case BlockStatement:
+			if ContainsYield(stmt.Stmts) {
+				return true
+			}
+		case WhileStatement:
+			if ContainsYield(stmt.Body.Stmts) {
+				return true
+			}
+		case ForInStatement:
+			if ContainsYield(stmt.Body.Stmts) {
+				return true
+			}
+		case IfStatement:
+			for _, ifBlock := range stmt.Ifs {
+				if ContainsYield(ifBlock.Body.Stmts) {
+					return true
+				}
+			}
+		case MatchStatement:
+			for _, c := range stmt.Cases {
+				if ContainsYield(c.Body.Stmts) {
+					return true
+				}
+			}
+			if stmt.Else != nil && ContainsYield(stmt.Else.Stmts) {
+				return true
+			}
+		case TryStatement:
+			if ContainsYield(stmt.Body.Stmts) || ContainsYield(stmt.Catch.Stmts) {
+				return true
+			}
+		case SelectStatement:
+			for _, c := range stmt.Cases {
+				if ContainsYield(c.Body.Stmts) {
+					return true
+				}
+			}
+			if stmt.Else != nil && ContainsYield(stmt.Else.Stmts) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -128,10 +128,46 @@ func TestParserErrors(t *testing.T) {
 			desc:  "eof on binary",
 			input: "1+",
 		},
+		{
+			desc:  "spawn without call",
+			input: "spawn 1 + 2;",
+		},
+		{
+			desc:  "select case without channel operation",
+			input: "select { case foo(a) { } }",
+		},
 		{
 			desc:  "no commas on function arguments",
 			input: "foo(1 2);",
 		},
+		{
+			desc:  "let without type after colon",
+			input: "let x: = 1;",
+		},
+		{
+			desc:  "map entry without colon",
+			input: `let m = {"a" 1};`,
+		},
+		{
+			desc:  "assignment to call",
+			input: `foo() = 1;`,
+		},
+		{
+			desc:  "try without catch",
+			input: `try { }`,
+		},
+		{
+			desc:  "unclosed index",
+			input: "xs[1;",
+		},
+		{
+			desc:  "statement inside enum",
+			input: "enum Color { Red let x = 1; }",
+		},
+		{
+			desc:  "statement inside class",
+			input: "class Point { let x = 1; }",
+		},
 	}
 	for _, tC := range testCases {
 		t.Run(tC.desc, func(t *testing.T) {
@@ -156,7 +192,7 @@ func TestStatements(t *testing.T) {
 			desc:  "let statement",
 			input: "let foo = -123;",
 			expected: []Statement{
-				LetStatement{AssignmentStatement{
+				LetStatement{AssignmentStatement: AssignmentStatement{
 					"foo",
 					Unary{
 						lexer.Token{lexer.Operator, "-", 1},
@@ -188,7 +224,7 @@ func TestStatements(t *testing.T) {
 			x = true;`,
 			expected: []Statement{
 				LetStatement{
-					AssignmentStatement{ "foo", Literal(lexer.Token{lexer.Number, "123", 1})},
+					AssignmentStatement: AssignmentStatement{ "foo", Literal(lexer.Token{lexer.Number, "123", 1})},
 				},
 				BlockStatement{
 					[]Statement{
@@ -432,7 +468,7 @@ func TestStatements(t *testing.T) {
 			input: `let bar = foo(1,someVariable);`,
 			expected: []Statement{
 				LetStatement{
-					AssignmentStatement{
+					AssignmentStatement: AssignmentStatement{
 						"bar",
 						FunctionCall{
 							"foo",
@@ -485,9 +521,9 @@ func TestStatements(t *testing.T) {
 			}`,
 			expected: []Statement{
 				FunctionDeclaration{
-					"foo",
-					[]string{"argx"},
-					BlockStatement{
+					Name: "foo",
+					Args: []string{"argx"},
+					Body: BlockStatement{
 						[]Statement{
 							AssignmentStatement{
 								"x",
@@ -505,9 +541,9 @@ func TestStatements(t *testing.T) {
 			}`,
 			expected: []Statement{
 				FunctionDeclaration{
-					"foo",
-					[]string{},
-					BlockStatement{
+					Name: "foo",
+					Args: []string{},
+					Body: BlockStatement{
 						[]Statement{
 							StatementExpression{
 								FunctionCall{
@@ -527,9 +563,9 @@ func TestStatements(t *testing.T) {
 			}`,
 			expected: []Statement{
 				FunctionDeclaration{
-					"foo",
-					[]string{"asd", "sad", "bar"},
-					BlockStatement{
+					Name: "foo",
+					Args: []string{"asd", "sad", "bar"},
+					Body: BlockStatement{
 						[]Statement{
 							AssignmentStatement{
 								"x",
@@ -548,6 +584,369 @@ func TestStatements(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "enum declaration",
+			input: `enum Shape { Circle(r), Rect(w, h), Empty }`,
+			expected: []Statement{
+				EnumDeclaration{
+					Name: "Shape",
+					Variants: []EnumVariant{
+						{Name: "Circle", Fields: []string{"r"}},
+						{Name: "Rect", Fields: []string{"w", "h"}},
+						{Name: "Empty", Fields: []string{}},
+					},
+				},
+			},
+		},
+		{
+			desc: "enum with methods",
+			input: `enum Vec { V(x, y) function __neg__(self) { } }`,
+			expected: []Statement{
+				EnumDeclaration{
+					Name:     "Vec",
+					Variants: []EnumVariant{{Name: "V", Fields: []string{"x", "y"}}},
+					Methods: []FunctionDeclaration{
+						{Name: "__neg__", Args: []string{"self"}, Body: BlockStatement{[]Statement{}}},
+					},
+				},
+			},
+		},
+		{
+			desc:  "class declaration",
+			input: `class Point { function init(self, x) { } function len(self) { } }`,
+			expected: []Statement{
+				ClassDeclaration{
+					Name: "Point",
+					Methods: []FunctionDeclaration{
+						{Name: "init", Args: []string{"self", "x"}, Body: BlockStatement{[]Statement{}}},
+						{Name: "len", Args: []string{"self"}, Body: BlockStatement{[]Statement{}}},
+					},
+				},
+			},
+		},
+		{
+			desc:  "property assignment",
+			input: `user.address.city = "Oslo";`,
+			expected: []Statement{
+				PropertyAssignmentStatement{
+					Target: Get{
+						Object: Get{
+							Object: Literal(lexer.Token{lexer.Identifier, "user", 1}),
+							Name:   lexer.Token{lexer.Identifier, "address", 1},
+						},
+						Name: lexer.Token{lexer.Identifier, "city", 1},
+					},
+					Expression: Literal(lexer.Token{lexer.StringLiteral, "Oslo", 1}),
+				},
+			},
+		},
+		{
+			desc: "index expression",
+			input: `xs[1][i + 1];`,
+			expected: []Statement{
+				StatementExpression{
+					Index{
+						Object: Index{
+							Object:  Literal(lexer.Token{lexer.Identifier, "xs", 1}),
+							Bracket: lexer.Token{lexer.Opening, "[", 1},
+							Index:   Literal(lexer.Token{lexer.Number, "1", 1}),
+						},
+						Bracket: lexer.Token{lexer.Opening, "[", 1},
+						Index: Binary{
+							lexer.Token{lexer.Operator, "+", 1},
+							Literal(lexer.Token{lexer.Identifier, "i", 1}),
+							Literal(lexer.Token{lexer.Number, "1", 1}),
+						},
+					},
+				},
+			},
+		},
+		{
+			desc:  "range membership",
+			input: `x in 0..<n + 1;`,
+			expected: []Statement{
+				StatementExpression{
+					Binary{
+						lexer.Token{lexer.Keyword, "in", 1},
+						Literal(lexer.Token{lexer.Identifier, "x", 1}),
+						Binary{
+							lexer.Token{lexer.Operator, "..<", 1},
+							Literal(lexer.Token{lexer.Number, "0", 1}),
+							Binary{
+								lexer.Token{lexer.Operator, "+", 1},
+								Literal(lexer.Token{lexer.Identifier, "n", 1}),
+								Literal(lexer.Token{lexer.Number, "1", 1}),
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc:  "map literal and index assignment",
+			input: `m["a"] = {"b": 1, 2: x};`,
+			expected: []Statement{
+				IndexAssignmentStatement{
+					Target: Index{
+						Object:  Literal(lexer.Token{lexer.Identifier, "m", 1}),
+						Bracket: lexer.Token{lexer.Opening, "[", 1},
+						Index:   Literal(lexer.Token{lexer.StringLiteral, "a", 1}),
+					},
+					Expression: MapLiteral{
+						Keys: []Expression{
+							Literal(lexer.Token{lexer.StringLiteral, "b", 1}),
+							Literal(lexer.Token{lexer.Number, "2", 1}),
+						},
+						Values: []Expression{
+							Literal(lexer.Token{lexer.Number, "1", 1}),
+							Literal(lexer.Token{lexer.Identifier, "x", 1}),
+						},
+					},
+				},
+			},
+		},
+		{
+			desc:  "try statement",
+			input: `try { foo(); } catch (e) { }`,
+			expected: []Statement{
+				TryStatement{
+					Body:  BlockStatement{[]Statement{StatementExpression{FunctionCall{"foo", []Expression{}}}}},
+					Name:  "e",
+					Catch: BlockStatement{[]Statement{}},
+				},
+			},
+		},
+		{
+			desc: "enum variant construction",
+			input: `let s = Shape.Circle(2);`,
+			expected: []Statement{
+				LetStatement{
+					AssignmentStatement: AssignmentStatement{
+						"s",
+						Call{
+							Callee: Get{
+								Object: Literal(lexer.Token{lexer.Identifier, "Shape", 1}),
+								Name:   lexer.Token{lexer.Identifier, "Circle", 1},
+							},
+							Args: []Expression{Literal(lexer.Token{lexer.Number, "2", 1})},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "match statement",
+			input: `match (s) {
+				case Circle(r) { x = r; }
+				else { x = 0; }
+			}`,
+			expected: []Statement{
+				MatchStatement{
+					Subject: Literal(lexer.Token{lexer.Identifier, "s", 1}),
+					Cases: []MatchCase{
+						{
+							Variant:  "Circle",
+							Bindings: []string{"r"},
+							Body: BlockStatement{
+								[]Statement{
+									AssignmentStatement{"x", Literal(lexer.Token{lexer.Identifier, "r", 2})},
+								},
+							},
+							Line: 2,
+						},
+					},
+					Else: &BlockStatement{
+						[]Statement{
+							AssignmentStatement{"x", Literal(lexer.Token{lexer.Number, "0", 3})},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "let list destructuring",
+			input: `let [a, b, ...rest] = [1, 2];`,
+			expected: []Statement{
+				LetDestructuringStatement{
+					DestructuringStatement{
+						Pattern: Pattern{Names: []string{"a", "b"}, Rest: "rest"},
+						Expression: ListLiteral{
+							[]Expression{
+								Literal(lexer.Token{lexer.Number, "1", 1}),
+								Literal(lexer.Token{lexer.Number, "2", 1}),
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "let object destructuring",
+			input: `let {name, age} = person;`,
+			expected: []Statement{
+				LetDestructuringStatement{
+					DestructuringStatement{
+						Pattern:    Pattern{Names: []string{"name", "age"}, Object: true},
+						Expression: Literal(lexer.Token{lexer.Identifier, "person", 1}),
+					},
+				},
+			},
+		},
+		{
+			desc: "swap",
+			input: `[a, b] = [b, a];`,
+			expected: []Statement{
+				DestructuringStatement{
+					Pattern: Pattern{Names: []string{"a", "b"}},
+					Expression: ListLiteral{
+						[]Expression{
+							Literal(lexer.Token{lexer.Identifier, "b", 1}),
+							Literal(lexer.Token{lexer.Identifier, "a", 1}),
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "list literal expression",
+			input: `[a, b];`,
+			expected: []Statement{
+				StatementExpression{
+					ListLiteral{
+						[]Expression{
+							Literal(lexer.Token{lexer.Identifier, "a", 1}),
+							Literal(lexer.Token{lexer.Identifier, "b", 1}),
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "for in statement",
+			input: `for (x in xs) {
+				yield x;
+				break;
+			}`,
+			expected: []Statement{
+				ForInStatement{
+					Name:     "x",
+					Iterable: Literal(lexer.Token{lexer.Identifier, "xs", 1}),
+					Body: BlockStatement{
+						[]Statement{
+							YieldStatement{Literal(lexer.Token{lexer.Identifier, "x", 2})},
+							BreakStatement{3},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "nil literal",
+			input: `x = nil;`,
+			expected: []Statement{
+				AssignmentStatement{"x", Literal(lexer.Token{lexer.Nil, "nil", 1})},
+			},
+		},
+		{
+			desc: "spawn statement",
+			input: `spawn worker(ch);`,
+			expected: []Statement{
+				SpawnStatement{
+					FunctionCall{"worker", []Expression{Literal(lexer.Token{lexer.Identifier, "ch", 1})}},
+				},
+			},
+		},
+		{
+			desc: "select statement",
+			input: `select {
+				case v = recv(a) { x = v; }
+				case send(b, 1) { }
+				else { }
+			}`,
+			expected: []Statement{
+				SelectStatement{
+					Cases: []SelectCase{
+						{
+							Name:    "v",
+							Channel: Literal(lexer.Token{lexer.Identifier, "a", 2}),
+							Body: BlockStatement{
+								[]Statement{
+									AssignmentStatement{"x", Literal(lexer.Token{lexer.Identifier, "v", 2})},
+								},
+							},
+						},
+						{
+							Send:    true,
+							Channel: Literal(lexer.Token{lexer.Identifier, "b", 3}),
+							Value:   Literal(lexer.Token{lexer.Number, "1", 3}),
+							Body:    BlockStatement{[]Statement{}},
+						},
+					},
+					Else: &BlockStatement{[]Statement{}},
+				},
+			},
+		},
+		{
+			desc: "async function with await and return",
+			input: `async function foo() {
+				let x = await bar();
+				return x;
+			}`,
+			expected: []Statement{
+				AsyncFunctionDeclaration{
+					FunctionDeclaration{
+						Name: "foo",
+						Args: []string{},
+						Body: BlockStatement{
+							[]Statement{
+								LetStatement{
+									AssignmentStatement: AssignmentStatement{
+										"x",
+										Await{
+											Keyword: lexer.Token{lexer.Keyword, "await", 2},
+											Ex:      FunctionCall{"bar", []Expression{}},
+										},
+									},
+								},
+								ReturnStatement{Expression: Literal(lexer.Token{lexer.Identifier, "x", 3}), Line: 3},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "empty return",
+			input: `return;`,
+			expected: []Statement{
+				ReturnStatement{Line: 1},
+			},
+		},
+		{
+			desc:  "let with type annotation",
+			input: `let x: number = 1;`,
+			expected: []Statement{
+				LetStatement{
+					AssignmentStatement: AssignmentStatement{"x", Literal(lexer.Token{lexer.Number, "1", 1})},
+					Type:                &TypeAnnotation{Name: "number", Line: 1},
+				},
+			},
+		},
+		{
+			desc:  "function with typed signature",
+			input: `function add(a: number, b): number { }`,
+			expected: []Statement{
+				FunctionDeclaration{
+					Name: "add",
+					Args: []string{"a", "b"},
+					Body: BlockStatement{[]Statement{}},
+					Signature: &Signature{
+						Args:   []*TypeAnnotation{{Name: "number", Line: 1}, nil},
+						Return: &TypeAnnotation{Name: "number", Line: 1},
+					},
+				},
+			},
+		},
 	}
 	for _, tC := range testCases {
 		t.Run(tC.desc, func(t *testing.T) {
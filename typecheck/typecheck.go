@@ -0,0 +1,765 @@
+package typecheck
+
+import (
+	"fmt"
+	"lox/lexer"
+	"lox/parser"
+	"strings"
+)
+
+// Type is a static type of an expression. Values of unannotated variables
+// and results of unknown functions have type Any, which is compatible with every type
+type Type string
+
+const (
+	Any       Type = "any"
+	Number    Type = "number"
+	String    Type = "string"
+	Bool      Type = "bool"
+	Nil       Type = "nil"
+	List      Type = "list"
+	Function  Type = "function"
+	Generator Type = "generator"
+	Promise   Type = "promise"
+	Channel   Type = "channel"
+	Range     Type = "range"
+	Map       Type = "map"
+)
+
+var builtinTypes = []Type{Any, Number, String, Bool, Nil, List, Function, Generator, Promise, Channel, Range, Map}
+
+func assignable(from, to Type) bool {
+	return from == Any || to == Any || from == to
+}
+
+type Error struct {
+	Line    int
+	Message string
+}
+
+func (e Error) Error() string {
+	if e.Line == 0 {
+		return "type error: " + e.Message
+	}
+	return fmt.Sprintf("type error at line %v: %v", e.Line, e.Message)
+}
+
+type signature struct {
+	args      []Type
+	ret       Type
+	async     bool
+	generator bool
+}
+
+type variable struct {
+	typ  Type
+	sig  *signature
+	enum bool
+}
+
+type scope struct {
+	vars      map[string]variable
+	enclosing *scope
+}
+
+func (s *scope) lookup(name string) (variable, bool) {
+	v, ok := s.vars[name]
+	if !ok && s.enclosing != nil {
+		return s.enclosing.lookup(name)
+	}
+	return v, ok
+}
+
+// Checker infers types of expressions and reports mismatches with type annotations.
+// Unannotated code is not checked, so it keeps its dynamic behavior
+type Checker struct {
+	scope   *scope
+	types   map[Type]bool
+	enums   map[Type][]parser.EnumVariant
+	returns []Type
+	errors  []error
+}
+
+// natives are signatures of functions from the standard library
+var natives = map[string]signature{
+	"len":        {args: []Type{Any}, ret: Number},
+	"substr":     {args: []Type{String, Number, Number}, ret: String},
+	"indexOf":    {args: []Type{String, String}, ret: Number},
+	"split":      {args: []Type{String, String}, ret: List},
+	"join":       {args: []Type{List, String}, ret: String},
+	"trim":       {args: []Type{String}, ret: String},
+	"upper":      {args: []Type{String}, ret: String},
+	"lower":      {args: []Type{String}, ret: String},
+	"replace":    {args: []Type{String, String, String}, ret: String},
+	"startsWith": {args: []Type{String, String}, ret: Bool},
+	"repeat":     {args: []Type{String, Number}, ret: String},
+	"chars":      {args: []Type{String}, ret: List},
+	"str":        {args: []Type{Any}, ret: String},
+	"num":        {args: []Type{Any}, ret: Number},
+	"readLine":   {args: []Type{}, ret: Any},
+	"readFile":   {args: []Type{String}, ret: String},
+	"writeFile":  {args: []Type{String, String}, ret: Nil},
+	"appendFile": {args: []Type{String, String}, ret: Nil},
+	"lines":      {args: []Type{String}, ret: Any},
+	"exists":     {args: []Type{String}, ret: Bool},
+	"getEnv":     {args: []Type{String}, ret: Any},
+	"keys":       {args: []Type{Map}, ret: List},
+	"values":     {args: []Type{Map}, ret: List},
+	"remove":     {args: []Type{Map, Any}, ret: Any},
+	"clock":      {args: []Type{}, ret: Number},
+	"now":        {args: []Type{}, ret: Number},
+	"sleep":      {args: []Type{Number}, ret: Nil},
+	"formatDate": {args: []Type{Number, String, String}, ret: String},
+	"parseDate":  {args: []Type{String, String, String}, ret: Number},
+}
+
+func Check(stmts []parser.Statement) []error {
+	return NewChecker().Check(stmts)
+}
+
+// NewChecker creates a checker which keeps declarations of the checked statements,
+// so sources run one after another by the same interpreter are checked together
+func NewChecker() *Checker {
+	builtins := &scope{vars: map[string]variable{}}
+	for name, sig := range natives {
+		sig := sig
+		builtins.vars[name] = variable{typ: Function, sig: &sig}
+	}
+
+	c := &Checker{
+		scope: &scope{vars: map[string]variable{}, enclosing: builtins},
+		types: map[Type]bool{},
+		enums: map[Type][]parser.EnumVariant{},
+	}
+	for _, t := range builtinTypes {
+		c.types[t] = true
+	}
+	return c
+}
+
+// Check checks the statements following the ones checked before. When there are
+// errors the statements don't run, so their declarations are forgotten
+func (c *Checker) Check(stmts []parser.Statement) []error {
+	vars, types, enums := clone(c.scope.vars), clone(c.types), clone(c.enums)
+	c.errors = nil
+	for _, s := range stmts {
+		s.AcceptStatement(c)
+	}
+
+	if len(c.errors) != 0 {
+		c.scope.vars, c.types, c.enums = vars, types, enums
+	}
+	return c.errors
+}
+
+// Declare declares a global defined outside of the checked sources, like a Go function or value set
+// by the embedding program. It replaces the signature of a native with the same name, calls of
+// declared functions and uses of values of type Any are not checked
+func (c *Checker) Declare(name string, t Type) {
+	c.scope.vars[name] = variable{typ: t}
+}
+
+func clone[K comparable, V any](m map[K]V) map[K]V {
+	out := make(map[K]V, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+func (c *Checker) report(line int, format string, args ...any) {
+	c.errors = append(c.errors, Error{Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *Checker) check(e parser.Expression) Type {
+	v, _ := e.AcceptExpr(c)
+	return v.(Type)
+}
+
+func (c *Checker) checkBlock(b parser.BlockStatement, vars map[string]variable) {
+	c.scope = &scope{vars: vars, enclosing: c.scope}
+	defer func() {
+		c.scope = c.scope.enclosing
+	}()
+
+	for _, s := range b.Stmts {
+		s.AcceptStatement(c)
+	}
+}
+
+func (c *Checker) checkPredicate(e parser.Expression, statement string) {
+	if t := c.check(e); !assignable(t, Bool) {
+		c.report(exprLine(e), "%v condition should be bool, got %v", statement, t)
+	}
+}
+
+func (c *Checker) resolve(annotation *parser.TypeAnnotation) Type {
+	if annotation == nil {
+		return Any
+	}
+
+	t := Type(annotation.Name)
+	if !c.types[t] {
+		c.report(annotation.Line, "unknown type %v", annotation.Name)
+		return Any
+	}
+	return t
+}
+
+func (c *Checker) VisitLiteral(li parser.Literal) (any, error) {
+	tok := lexer.Token(li)
+	switch tok.TokType {
+	case lexer.Number:
+		return Number, nil
+	case lexer.StringLiteral:
+		return String, nil
+	case lexer.Boolean:
+		return Bool, nil
+	case lexer.Nil:
+		return Nil, nil
+	case lexer.Identifier:
+		if v, ok := c.scope.lookup(tok.Lexeme); ok {
+			return v.typ, nil
+		}
+	}
+	return Any, nil
+}
+
+// isUserType reports whether the type is an enum or a class, their values may overload operators
+func (c *Checker) isUserType(t Type) bool {
+	for _, b := range builtinTypes {
+		if t == b {
+			return false
+		}
+	}
+	return c.types[t]
+}
+
+// declared reports whether the type of the expression comes from an annotation or a signature.
+// Operands typed only by literals are not reported, like the rest of unannotated code they may never run
+func (c *Checker) declared(e parser.Expression) bool {
+	switch e := e.(type) {
+	case parser.Literal:
+		if e.TokType != lexer.Identifier {
+			return false
+		}
+		v, ok := c.scope.lookup(e.Lexeme)
+		return ok && v.typ != Any
+	case parser.FunctionCall:
+		v, ok := c.scope.lookup(e.Name)
+		return ok && v.sig != nil
+	case parser.Unary:
+		return c.declared(e.Ex)
+	case parser.Binary:
+		return c.declared(e.Left) || c.declared(e.Right)
+	case parser.Await:
+		return c.declared(e.Ex)
+	}
+	return false
+}
+
+func (c *Checker) VisitUnary(u parser.Unary) (any, error) {
+	t := c.check(u.Ex)
+	if c.isUserType(t) {
+		return Any, nil
+	}
+	expected := Number
+	if u.Op.Lexeme == "!" {
+		expected = Bool
+	}
+
+	if !assignable(t, expected) && c.declared(u.Ex) {
+		c.report(u.Op.Line, "operator %v expects %v, got %v", u.Op.Lexeme, expected, t)
+	}
+	return expected, nil
+}
+
+func (c *Checker) VisitBinary(b parser.Binary) (any, error) {
+	left := c.check(b.Left)
+	right := c.check(b.Right)
+	mismatch := func() (any, error) {
+		if c.declared(b.Left) || c.declared(b.Right) {
+			c.report(b.Op.Line, "operator %v can't be applied to %v and %v", b.Op.Lexeme, left, right)
+		}
+		return Any, nil
+	}
+	both := func(t Type) bool {
+		return assignable(left, t) && assignable(right, t)
+	}
+	if c.isUserType(left) || c.isUserType(right) {
+		return Any, nil
+	}
+
+	switch b.Op.Lexeme {
+	case "+":
+		if left == Any || right == Any {
+			if both(Number) || both(String) {
+				return Any, nil
+			}
+			return mismatch()
+		} else if left == right && (left == Number || left == String) {
+			return left, nil
+		}
+		return mismatch()
+	case "-", "*", "/", "%":
+		if !both(Number) {
+			return mismatch()
+		}
+		return Number, nil
+	case ">", ">=", "<", "<=":
+		if !both(Number) {
+			return mismatch()
+		}
+		return Bool, nil
+	case "..", "..<":
+		if !both(Number) {
+			return mismatch()
+		}
+		return Range, nil
+	case "in":
+		return Bool, nil
+	case "&&", "||":
+		if !both(Bool) {
+			return mismatch()
+		}
+		return Bool, nil
+	case "==", "!=":
+		if left != Nil && right != Nil && !assignable(left, right) {
+			return mismatch()
+		}
+		return Bool, nil
+	}
+	return Any, nil
+}
+
+func (c *Checker) VisitFunctionCall(call parser.FunctionCall) (any, error) {
+	args := []Type{}
+	for _, a := range call.Args {
+		args = append(args, c.check(a))
+	}
+
+	v, ok := c.scope.lookup(call.Name)
+	if !ok || v.sig == nil {
+		return Any, nil
+	}
+
+	line := 0
+	if len(call.Args) != 0 {
+		line = exprLine(call.Args[0])
+	}
+	if len(args) != len(v.sig.args) {
+		c.report(line, "function %v expects %d arguments, got %d", call.Name, len(v.sig.args), len(args))
+		return v.resultType(), nil
+	}
+	for j, a := range args {
+		if !assignable(a, v.sig.args[j]) {
+			c.report(exprLine(call.Args[j]), "argument %d of function %v should be %v, got %v", j+1, call.Name, v.sig.args[j], a)
+		}
+	}
+	return v.resultType(), nil
+}
+
+func (v variable) resultType() Type {
+	if v.sig.generator {
+		return Generator
+	} else if v.sig.async {
+		return Promise
+	}
+	return v.sig.ret
+}
+
+func (c *Checker) VisitCall(call parser.Call) (any, error) {
+	callee := c.check(call.Callee)
+	for _, a := range call.Args {
+		c.check(a)
+	}
+
+	// payload variants of enums are constructors
+	if get, ok := call.Callee.(parser.Get); ok && callee != Any && c.types[callee] {
+		if _, isEnum := c.enumName(get.Object); isEnum {
+			return callee, nil
+		}
+	}
+	return Any, nil
+}
+
+func (c *Checker) enumName(e parser.Expression) (string, bool) {
+	li, ok := e.(parser.Literal)
+	if !ok || li.TokType != lexer.Identifier {
+		return "", false
+	}
+	v, ok := c.scope.lookup(li.Lexeme)
+	return li.Lexeme, ok && v.enum
+}
+
+func (c *Checker) VisitGet(g parser.Get) (any, error) {
+	c.check(g.Object)
+	if name, ok := c.enumName(g.Object); ok {
+		return Type(name), nil
+	}
+	return Any, nil
+}
+
+func (c *Checker) VisitListLiteral(l parser.ListLiteral) (any, error) {
+	for _, e := range l.Elements {
+		c.check(e)
+	}
+	return List, nil
+}
+
+func (c *Checker) VisitIndex(ix parser.Index) (any, error) {
+	t := c.check(ix.Object)
+	index := c.check(ix.Index)
+	if index == Range && (t == List || t == String) {
+		return t, nil
+	} else if t == List && !assignable(index, Number) && index != Range {
+		c.report(ix.Bracket.Line, "list index should be number or range, got %v", index)
+	}
+	return Any, nil
+}
+
+func (c *Checker) VisitMapLiteral(m parser.MapLiteral) (any, error) {
+	for j := range m.Keys {
+		c.check(m.Keys[j])
+		c.check(m.Values[j])
+	}
+	return Map, nil
+}
+
+func (c *Checker) VisitAwait(a parser.Await) (any, error) {
+	t := c.check(a.Ex)
+	if call, ok := a.Ex.(parser.FunctionCall); ok && t == Promise {
+		if v, ok := c.scope.lookup(call.Name); ok && v.sig != nil && v.sig.async {
+			return v.sig.ret, nil
+		}
+	} else if t != Promise {
+		return t, nil
+	}
+	return Any, nil
+}
+
+func (c *Checker) VisitStatementExpression(s parser.StatementExpression) error {
+	c.check(s.Expression)
+	return nil
+}
+
+func (c *Checker) VisitLetStatement(let parser.LetStatement) error {
+	t := c.check(let.Expression)
+	declared := c.resolve(let.Type)
+	if !assignable(t, declared) {
+		c.report(let.Type.Line, "can't assign %v to %v of type %v", t, let.Name, declared)
+	}
+	c.scope.vars[let.Name] = variable{typ: declared}
+	return nil
+}
+
+func (c *Checker) VisitAssignmentStatement(assign parser.AssignmentStatement) error {
+	t := c.check(assign.Expression)
+	if v, ok := c.scope.lookup(assign.Name); ok && !assignable(t, v.typ) {
+		c.report(exprLine(assign.Expression), "can't assign %v to %v of type %v", t, assign.Name, v.typ)
+	}
+	return nil
+}
+
+func (c *Checker) VisitBlockStatement(b parser.BlockStatement) error {
+	c.checkBlock(b, map[string]variable{})
+	return nil
+}
+
+func (c *Checker) VisitIfStatement(ifStmt parser.IfStatement) error {
+	for _, ifEl := range ifStmt.Ifs {
+		c.checkPredicate(ifEl.Predicate, "if")
+		c.checkBlock(ifEl.Body, map[string]variable{})
+	}
+	return nil
+}
+
+func (c *Checker) VisitWhileStatement(w parser.WhileStatement) error {
+	c.checkPredicate(w.Predicate, "while")
+	c.checkBlock(w.Body, map[string]variable{})
+	return nil
+}
+
+func (c *Checker) checkFunction(fn parser.FunctionDeclaration, async bool) {
+	sig := &signature{ret: Any, async: async, generator: parser.ContainsYield(fn.Body.Stmts)}
+	params := map[string]variable{}
+	for j, name := range fn.Args {
+		t := Any
+		if fn.Signature != nil {
+			t = c.resolve(fn.Signature.Args[j])
+		}
+		sig.args = append(sig.args, t)
+		params[name] = variable{typ: t}
+	}
+	if fn.Signature != nil {
+		sig.ret = c.resolve(fn.Signature.Return)
+	}
+
+	// declared before the body is checked, so recursive calls are checked too
+	c.scope.vars[fn.Name] = variable{typ: Function, sig: sig}
+
+	c.returns = append(c.returns, sig.ret)
+	c.checkBlock(fn.Body, params)
+	c.returns = c.returns[:len(c.returns)-1]
+}
+
+func (c *Checker) VisitFunctionDeclarationStatement(fn parser.FunctionDeclaration) error {
+	c.checkFunction(fn, false)
+	return nil
+}
+
+func (c *Checker) VisitAsyncFunctionDeclarationStatement(fn parser.AsyncFunctionDeclaration) error {
+	c.checkFunction(fn.FunctionDeclaration, true)
+	return nil
+}
+
+func (c *Checker) VisitReturnStatement(r parser.ReturnStatement) error {
+	t := Nil
+	if r.Expression != nil {
+		t = c.check(r.Expression)
+	}
+
+	if len(c.returns) == 0 {
+		return nil
+	} else if expected := c.returns[len(c.returns)-1]; !assignable(t, expected) {
+		c.report(r.Line, "function should return %v, got %v", expected, t)
+	}
+	return nil
+}
+
+func (c *Checker) VisitIndexAssignmentStatement(a parser.IndexAssignmentStatement) error {
+	c.check(a.Target)
+	c.check(a.Expression)
+	return nil
+}
+
+func (c *Checker) VisitTryStatement(t parser.TryStatement) error {
+	c.checkBlock(t.Body, map[string]variable{})
+	c.checkBlock(t.Catch, map[string]variable{t.Name: {typ: Any}})
+	return nil
+}
+
+func (c *Checker) VisitEnumDeclarationStatement(e parser.EnumDeclaration) error {
+	c.types[Type(e.Name)] = true
+	c.enums[Type(e.Name)] = e.Variants
+	c.scope.vars[e.Name] = variable{typ: Any, enum: true}
+
+	// methods are not visible as functions
+	c.scope = &scope{vars: map[string]variable{}, enclosing: c.scope}
+	for _, m := range e.Methods {
+		c.checkFunction(m, false)
+	}
+	c.scope = c.scope.enclosing
+	return nil
+}
+
+func (c *Checker) VisitClassDeclarationStatement(cl parser.ClassDeclaration) error {
+	c.types[Type(cl.Name)] = true
+	// the class is called with the arguments of init without the instance,
+	// declared before the methods are checked, so they can create instances too
+	sig := &signature{args: []Type{}, ret: Type(cl.Name)}
+	for _, m := range cl.Methods {
+		for j := 1; m.Name == "init" && j < len(m.Args); j++ {
+			sig.args = append(sig.args, Any)
+		}
+	}
+	c.scope.vars[cl.Name] = variable{typ: Function, sig: sig}
+
+	// methods are not visible as functions
+	c.scope = &scope{vars: map[string]variable{}, enclosing: c.scope}
+	for _, m := range cl.Methods {
+		c.checkFunction(m, false)
+		if init := c.scope.vars[m.Name]; m.Name == "init" && len(init.sig.args) != 0 {
+			sig.args = init.sig.args[1:]
+		}
+	}
+	c.scope = c.scope.enclosing
+	return nil
+}
+
+// VisitPropertyAssignmentStatement reports targets which can't have fields, only instances of classes
+// and bound Go structs can. Like with operators, targets typed only by literals are not reported
+func (c *Checker) VisitPropertyAssignmentStatement(a parser.PropertyAssignmentStatement) error {
+	t := c.check(a.Target.Object)
+	c.check(a.Expression)
+
+	_, enum := c.enums[t]
+	if t != Any && (enum || !c.isUserType(t) && c.declared(a.Target.Object)) {
+		c.report(a.Target.Name.Line, "can't set property %v of %v", a.Target.Name.Lexeme, t)
+	}
+	return nil
+}
+
+func (c *Checker) VisitMatchStatement(m parser.MatchStatement) error {
+	if enum, ok := c.matchedEnum(c.check(m.Subject), m.Cases); ok {
+		c.checkCases(enum, m)
+	}
+	for _, mc := range m.Cases {
+		bindings := map[string]variable{}
+		for _, b := range mc.Bindings {
+			bindings[b] = variable{typ: Any}
+		}
+		c.checkBlock(mc.Body, bindings)
+	}
+	if m.Else != nil {
+		c.checkBlock(*m.Else, map[string]variable{})
+	}
+	return nil
+}
+
+// matchedEnum finds the enum of the match subject. When the type of the subject
+// isn't known, it's the only enum declared so far with a variant named in the cases
+func (c *Checker) matchedEnum(t Type, cases []parser.MatchCase) (Type, bool) {
+	if _, ok := c.enums[t]; ok || t != Any {
+		return t, ok
+	}
+
+	found := []Type{}
+	for enum, variants := range c.enums {
+		if hasVariant(variants, cases) {
+			found = append(found, enum)
+		}
+	}
+	if len(found) != 1 {
+		return Any, false
+	}
+	return found[0], true
+}
+
+func hasVariant(variants []parser.EnumVariant, cases []parser.MatchCase) bool {
+	for _, mc := range cases {
+		for _, v := range variants {
+			if v.Name == mc.Variant {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkCases reports unknown and duplicated variants, wrong number of bindings and
+// missing variants, like matching does at runtime
+func (c *Checker) checkCases(enum Type, m parser.MatchStatement) {
+	fields := map[string]int{}
+	for _, v := range c.enums[enum] {
+		fields[v.Name] = len(v.Fields)
+	}
+
+	covered := map[string]bool{}
+	for _, mc := range m.Cases {
+		n, ok := fields[mc.Variant]
+		if !ok {
+			c.report(mc.Line, "enum %v has no variant %v", enum, mc.Variant)
+		} else if covered[mc.Variant] {
+			c.report(mc.Line, "duplicated case %v in match on enum %v", mc.Variant, enum)
+		} else if n != len(mc.Bindings) {
+			c.report(mc.Line, "variant %v.%v has %d fields, got %d bindings", enum, mc.Variant, n, len(mc.Bindings))
+		}
+		covered[mc.Variant] = true
+	}
+	if m.Else != nil {
+		return
+	}
+
+	missing := []string{}
+	for _, v := range c.enums[enum] {
+		if !covered[v.Name] {
+			missing = append(missing, v.Name)
+		}
+	}
+	if len(missing) != 0 {
+		c.report(exprLine(m.Subject), "non exhaustive match on enum %v, missing variants: %v", enum, strings.Join(missing, ", "))
+	}
+}
+
+func (c *Checker) checkDestructuring(d parser.DestructuringStatement) {
+	t := c.check(d.Expression)
+	if !d.Pattern.Object && !assignable(t, List) {
+		c.report(exprLine(d.Expression), "can't destructure %v into list pattern", t)
+	}
+}
+
+func (c *Checker) VisitDestructuringStatement(d parser.DestructuringStatement) error {
+	c.checkDestructuring(d)
+	return nil
+}
+
+func (c *Checker) VisitLetDestructuringStatement(let parser.LetDestructuringStatement) error {
+	c.checkDestructuring(let.DestructuringStatement)
+	for _, name := range let.Pattern.Names {
+		c.scope.vars[name] = variable{typ: Any}
+	}
+	if let.Pattern.Rest != "" {
+		c.scope.vars[let.Pattern.Rest] = variable{typ: List}
+	}
+	return nil
+}
+
+func (c *Checker) VisitForInStatement(f parser.ForInStatement) error {
+	t := c.check(f.Iterable)
+	if t != Any && t != List && t != Generator && t != Range && t != Map && !c.isUserType(t) {
+		c.report(exprLine(f.Iterable), "%v is not iterable", t)
+	}
+	c.checkBlock(f.Body, map[string]variable{f.Name: {typ: Any}})
+	return nil
+}
+
+func (c *Checker) VisitYieldStatement(y parser.YieldStatement) error {
+	c.check(y.Expression)
+	return nil
+}
+
+func (c *Checker) VisitBreakStatement(parser.BreakStatement) error {
+	return nil
+}
+
+func (c *Checker) VisitSpawnStatement(s parser.SpawnStatement) error {
+	c.check(s.Call)
+	return nil
+}
+
+func (c *Checker) VisitSelectStatement(s parser.SelectStatement) error {
+	for _, sc := range s.Cases {
+		c.check(sc.Channel)
+		if sc.Value != nil {
+			c.check(sc.Value)
+		}
+
+		vars := map[string]variable{}
+		if sc.Name != "" {
+			vars[sc.Name] = variable{typ: Any}
+		}
+		c.checkBlock(sc.Body, vars)
+	}
+	if s.Else != nil {
+		c.checkBlock(*s.Else, map[string]variable{})
+	}
+	return nil
+}
+
+// exprLine finds line of the first token of the expression, 0 if unknown
+func exprLine(e parser.Expression) int {
+	switch ex := e.(type) {
+	case parser.Literal:
+		return ex.Line
+	case parser.Unary:
+		return ex.Op.Line
+	case parser.Binary:
+		return exprLine(ex.Left)
+	case parser.Get:
+		return exprLine(ex.Object)
+	case parser.Call:
+		return exprLine(ex.Callee)
+	case parser.Await:
+		return ex.Keyword.Line
+	case parser.FunctionCall:
+		for _, a := range ex.Args {
+			return exprLine(a)
+		}
+	case parser.ListLiteral:
+		for _, el := range ex.Elements {
+			return exprLine(el)
+		}
+	}
+	return 0
+}
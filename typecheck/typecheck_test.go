@@ -0,0 +1,223 @@
+package typecheck
+
+import (
+	"lox/lexer"
+	"lox/parser"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func check(t *testing.T, input string) []string {
+	toks, err := lexer.Lex(input)
+	require.NoError(t, err, "got lexer error")
+	stmts, errs := parser.NewParser(toks).Parse()
+	require.Empty(t, errs, "got parser errors")
+
+	out := []string{}
+	for _, e := range Check(stmts) {
+		out = append(out, e.Error())
+	}
+	return out
+}
+
+func TestCheck(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		input    string
+		expected []string
+	}{
+		{
+			desc:     "unannotated code is not checked",
+			input:    `let x = 1; x = "foo"; let y = x + 1;`,
+			expected: []string{},
+		},
+		{
+			desc:     "annotated let",
+			input:    `let x: number = 1 + 2; let s: string = "a" + "b"; let b: bool = x > 1;`,
+			expected: []string{},
+		},
+		{
+			desc:     "annotated let with wrong type",
+			input:    `let x: number = "foo";`,
+			expected: []string{"type error at line 1: can't assign string to x of type number"},
+		},
+		{
+			desc: "assignment to annotated variable",
+			input: `let x: number = 1;
+			x = true;`,
+			expected: []string{"type error at line 2: can't assign bool to x of type number"},
+		},
+		{
+			desc:     "unknown type",
+			input:    `let x: foo = 1;`,
+			expected: []string{"type error at line 1: unknown type foo"},
+		},
+		{
+			desc:     "mismatched operands",
+			input:    `let n: number = 1; let x = n + "foo"; let y = !n; let z = len("a") - "b";`,
+			expected: []string{
+				"type error at line 1: operator + can't be applied to number and string",
+				"type error at line 1: operator ! expects bool, got number",
+				"type error at line 1: operator - can't be applied to number and string",
+			},
+		},
+		{
+			desc:     "operands typed only by literals are not checked",
+			input:    `if (false) { "a" - 1; let x = 1 + "foo"; let y = !1; let z = -("a" + "b"); }`,
+			expected: []string{},
+		},
+		{
+			desc:     "non bool condition",
+			input:    `if (1) { } while ("foo") { }`,
+			expected: []string{"type error at line 1: if condition should be bool, got number", "type error at line 1: while condition should be bool, got string"},
+		},
+		{
+			desc: "function signature",
+			input: `function add(a: number, b: number): number {
+				return a + b;
+			}
+			let x: number = add(1, 2);
+			let y: string = add(1, 2);
+			add("foo", 2);
+			add(1);`,
+			expected: []string{
+				"type error at line 5: can't assign number to y of type string",
+				"type error at line 6: argument 1 of function add should be number, got string",
+				"type error at line 7: function add expects 2 arguments, got 1",
+			},
+		},
+		{
+			desc: "return type",
+			input: `function foo(): string {
+				return 1;
+			}`,
+			expected: []string{"type error at line 2: function should return string, got number"},
+		},
+		{
+			desc: "parameters are typed inside the body",
+			input: `function foo(a: string, b) {
+				let x: number = a;
+				let y: number = b;
+			}`,
+			expected: []string{"type error at line 2: can't assign string to x of type number"},
+		},
+		{
+			desc: "enum types",
+			input: `enum Shape { Circle(r), Square(side) }
+			let s: Shape = Shape.Circle(1);
+			let n: number = Shape.Square(2);`,
+			expected: []string{"type error at line 3: can't assign Shape to n of type number"},
+		},
+		{
+			desc: "match on enum",
+			input: `enum Color { Red, Green, Blue }
+			enum Shape { Circle(r), Square(side) }
+			let c = Color.Red;
+			match (c) {
+				case Red { }
+				case Gren { }
+				case Red { }
+			}
+			let s: Shape = Shape.Circle(1);
+			match (s) {
+				case Circle(r, x) { }
+				else { }
+			}
+			match (s) {
+				case Square(side) { }
+			}`,
+			expected: []string{
+				"type error at line 6: enum Color has no variant Gren",
+				"type error at line 7: duplicated case Red in match on enum Color",
+				"type error at line 4: non exhaustive match on enum Color, missing variants: Green, Blue",
+				"type error at line 11: variant Shape.Circle has 1 fields, got 2 bindings",
+				"type error at line 14: non exhaustive match on enum Shape, missing variants: Circle",
+			},
+		},
+		{
+			desc: "enums may overload operators",
+			input: `enum Vec {
+				V(x, y)
+				function __add__(self, other): number { return "foo"; }
+			}
+			let v: Vec = Vec.V(1, 2);
+			let w = v + v;
+			let n = -v;`,
+			expected: []string{"type error at line 3: function should return number, got string"},
+		},
+		{
+			desc: "classes",
+			input: `class Point {
+				function init(self, x: number, y: number) { self.x = x; self.y = y; }
+				function __add__(self, other) { return Point(self.x + other.x, self.y + other.y); }
+			}
+			let p: Point = Point(1, 2);
+			let q = p + p;
+			let n: number = Point(1, 2);
+			let r = Point("a", 2);
+			let s = Point(1);`,
+			expected: []string{
+				"type error at line 7: can't assign Point to n of type number",
+				"type error at line 8: argument 1 of function Point should be number, got string",
+				"type error at line 9: function Point expects 2 arguments, got 1",
+			},
+		},
+		{
+			desc: "property assignment",
+			input: `class P { function init(self) { self.x = 1; } }
+			let p: P = P(); p.y = 2;
+			let n: number = 1; n.x = 2;
+			enum Color { Red } Color.Red.x = 1;
+			let s = "a"; s.x = 1;
+			let m: map = {}; m.x = 1;`,
+			expected: []string{
+				"type error at line 3: can't set property x of number",
+				"type error at line 4: can't set property x of Color",
+				"type error at line 6: can't set property x of map",
+			},
+		},
+		{
+			desc:     "list index",
+			input:    `let xs: list = [1]; let x = xs["a"];`,
+			expected: []string{"type error at line 1: list index should be number or range, got string"},
+		},
+		{
+			desc: "async and generator functions",
+			input: `async function foo(): number { return 1; }
+			function gen() { yield 1; }
+			let p: promise = foo();
+			let n: number = await foo();
+			let g: generator = gen();`,
+			expected: []string{},
+		},
+		{
+			desc:     "ranges",
+			input:    `let r: range = 0..<10; let xs: list = [1, 2][0..1]; let s: string = "abc"[1..2]; let b: bool = 1 in r; let x = len("a").."a";`,
+			expected: []string{"type error at line 1: operator .. can't be applied to number and string"},
+		},
+		{
+			desc:     "natives",
+			input:    `let n: number = len("abc"); let s: string = upper(1); let len = 1;`,
+			expected: []string{"type error at line 1: argument 1 of function upper should be string, got number"},
+		},
+		{
+			desc: "maps and try",
+			input: `let m: map = {"a": 1};
+			m["b"] = 2;
+			try { let k: number = keys(m); } catch (e) { let s: string = e; }`,
+			expected: []string{"type error at line 3: can't assign list to k of type number"},
+		},
+		{
+			desc:     "iterating a number",
+			input:    `for (x in 1) { }`,
+			expected: []string{"type error at line 1: number is not iterable"},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			assert.Equal(t, tC.expected, check(t, tC.input))
+		})
+	}
+}